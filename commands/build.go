@@ -0,0 +1,204 @@
+package commands
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/merge"
+	"github.com/jtarchie/tile-builder/metadata"
+)
+
+type Build struct {
+	Paths                 []string          `long:"path" required:"true" description:"path to a bosh release tarball (can be specified multiple times)"`
+	MergingFile           string            `long:"merge" description:"yaml file to merge results with, merging lists of named elements by name"`
+	MergeMode             string            `long:"merge-mode" default:"override" choice:"override" choice:"fill" description:"whether the merged file overrides the generated values, or only fills in missing ones"`
+	OpsFiles              []string          `long:"ops-file" description:"bosh ops-file of replace and remove operations to apply to the results (can be specified multiple times)"`
+	Output                string            `long:"output" required:"true" description:"path to write the .pivotal file to"`
+	Migrations            string            `long:"migrations" description:"directory of javascript migrations to package into migrations/v1"`
+	Links                 map[string]string `long:"cross-deployment-link" description:"consume a link from another product's deployment, as link:product (can be specified multiple times)"`
+	RulesFile             string            `long:"rules" description:"yaml file of rules to infer property blueprints with"`
+	FailOnUnresolvedLinks bool              `long:"fail-on-unresolved-links" description:"fail when a required link is not provided by any job"`
+	JobScoped             bool              `long:"job-scoped" description:"create the property blueprints on each job type, instead of one global property namespace"`
+	GroupBy               string            `long:"group-by" choice:"prefix" choice:"job" description:"group the properties into forms by name prefix or by job (default: job when job scoped, prefix otherwise)"`
+	GroupDepth            int               `long:"group-depth" default:"1" description:"number of name segments to group the properties by prefix with"`
+	GroupsFile            string            `long:"groups" description:"yaml file of forms and the regular expressions of the properties they group"`
+	Stdout                io.Writer
+	Stderr                io.Writer
+}
+
+func (b Build) Execute(_ []string) error {
+	var releases []generator.BoshReleasePayload
+
+	// releasePaths are the tarballs by the file metadata.yml references
+	releasePaths := map[string]string{}
+
+	for _, path := range b.Paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("could not stat release %s: %s", path, err)
+		}
+
+		if info.IsDir() {
+			return fmt.Errorf("release %s must be a tarball to be packaged", path)
+		}
+
+		release, err := generator.ParseRelease(path)
+		if err != nil {
+			return fmt.Errorf("tile creation failed: %s", err)
+		}
+
+		if previous, found := releasePaths[release.File]; found {
+			return fmt.Errorf("releases %s and %s would both be packaged as %s", previous, path, release.File)
+		}
+
+		releasePaths[release.File] = path
+		releases = append(releases, release)
+	}
+
+	printStemcellWarnings(b.Stderr, releases)
+
+	options, err := tileOptions(generator.Options{
+		CrossDeploymentLinks: b.Links,
+		JobScoped:            b.JobScoped,
+		Grouping: generator.Grouping{
+			By:    b.GroupBy,
+			Depth: b.GroupDepth,
+		},
+	}, b.RulesFile, b.GroupsFile)
+	if err != nil {
+		return err
+	}
+
+	if !options.JobScoped {
+		printPropertyClashes(b.Stderr, releases)
+	}
+
+	printRuleMatches(b.Stderr, options, releases)
+
+	err = reportLinks(b.Stderr, options, releases, b.FailOnUnresolvedLinks)
+	if err != nil {
+		return err
+	}
+
+	contents, err := marshalTile(options, releases)
+	if err != nil {
+		return fmt.Errorf("tile creation failed: %s", err)
+	}
+
+	contents, err = applyOverlays(contents, b.MergingFile, merge.Mode(b.MergeMode), b.OpsFiles)
+	if err != nil {
+		return fmt.Errorf("cannot merge file: %s", err)
+	}
+
+	err = writeProductFile(b.Output, contents, releases, releasePaths, b.Migrations)
+	if err != nil {
+		return fmt.Errorf("could not write product file %s: %s", b.Output, err)
+	}
+
+	source, err := metadata.ReadTile(b.Output)
+	if err != nil {
+		return err
+	}
+
+	payload, err := metadata.Parse(source.Contents, false)
+	if err != nil {
+		return fmt.Errorf("could not unmarshal %s: %s", source.Filename, err)
+	}
+
+	validations, err := payload.ValidateWithSource(source.Contents)
+	if err != nil {
+		return fmt.Errorf("could not determine validations on tile: %s", err)
+	}
+
+	if len(validations) > 0 {
+		printValidations(b.Stdout, source, validations)
+
+		return fmt.Errorf("tile %s has %d validation error(s)", b.Output, len(validations))
+	}
+
+	_, _ = fmt.Fprintf(b.Stdout, "built %s\n", b.Output)
+
+	return nil
+}
+
+func writeProductFile(productPath string, metadataContents []byte, releases []generator.BoshReleasePayload, releasePaths map[string]string, migrationsDir string) error {
+	file, err := os.Create(productPath)
+	if err != nil {
+		return err
+	}
+
+	err = writeProductArchive(file, metadataContents, releases, releasePaths, migrationsDir)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func writeProductArchive(file io.Writer, metadataContents []byte, releases []generator.BoshReleasePayload, releasePaths map[string]string, migrationsDir string) error {
+	archive := zip.NewWriter(file)
+
+	writer, err := archive.Create("metadata/metadata.yml")
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(metadataContents)
+	if err != nil {
+		return err
+	}
+
+	for _, release := range releases {
+		writer, err := archive.Create(fmt.Sprintf("releases/%s", release.File))
+		if err != nil {
+			return err
+		}
+
+		err = copyFile(writer, releasePaths[release.File])
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = archive.Create("migrations/v1/")
+	if err != nil {
+		return err
+	}
+
+	if migrationsDir != "" {
+		migrationPaths, err := filepath.Glob(filepath.Join(migrationsDir, "*.js"))
+		if err != nil {
+			return err
+		}
+
+		for _, migrationPath := range migrationPaths {
+			writer, err := archive.Create(fmt.Sprintf("migrations/v1/%s", filepath.Base(migrationPath)))
+			if err != nil {
+				return err
+			}
+
+			err = copyFile(writer, migrationPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return archive.Close()
+}
+
+func copyFile(writer io.Writer, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = io.Copy(writer, file)
+	return err
+}
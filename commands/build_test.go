@@ -0,0 +1,222 @@
+package commands_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jtarchie/tile-builder/commands"
+	"github.com/jtarchie/tile-builder/metadata"
+	"github.com/mholt/archiver"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Build", func() {
+	It("packages the releases and metadata into a .pivotal", func() {
+		stdout := gbytes.NewBuffer()
+		releasePath := createReleaseTarball("my-release", "1.0.0")
+		productPath := filepath.Join(tempDir(), "product.pivotal")
+
+		command := commands.Build{
+			Paths:       []string{releasePath},
+			MergingFile: writeFile(buildOverlay),
+			Output:      productPath,
+			Stdout:      stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(gbytes.Say("built"))
+
+		dir := tempDir()
+		err = archiver.NewZip().Unarchive(productPath, dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(dir, "metadata", "metadata.yml")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "releases", "my-release-1.0.0.tgz")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "migrations", "v1")).To(BeADirectory())
+
+		payload, err := metadata.FromTile(productPath, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload.Name).To(Equal("example-tile"))
+		Expect(payload.JobTypes).To(HaveLen(1))
+		Expect(payload.JobTypes[0].Templates[0].Release).To(Equal("my-release"))
+		Expect(payload.Releases).To(HaveLen(1))
+		Expect(payload.Releases[0].File).To(Equal("my-release-1.0.0.tgz"))
+		Expect(payload.Releases[0].Version).To(Equal("1.0.0"))
+		Expect(payload.Releases[0].SHA1).NotTo(BeEmpty())
+	})
+
+	It("packages releases by their name and version, and fails on duplicates", func() {
+		productPath := filepath.Join(tempDir(), "product.pivotal")
+		first, second := createReleaseTarball("my-release", "1.0.0"), createReleaseTarball("other-release", "2.0.0", "other")
+		Expect(filepath.Base(first)).To(Equal(filepath.Base(second)))
+
+		command := commands.Build{
+			Paths:       []string{first, second},
+			MergingFile: writeFile(buildOverlay),
+			Output:      productPath,
+			Stdout:      gbytes.NewBuffer(),
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir := tempDir()
+		err = archiver.NewZip().Unarchive(productPath, dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(dir, "releases", "my-release-1.0.0.tgz")).To(BeAnExistingFile())
+		Expect(filepath.Join(dir, "releases", "other-release-2.0.0.tgz")).To(BeAnExistingFile())
+
+		command.Paths = []string{first, createReleaseTarball("my-release", "1.0.0")}
+		err = command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring("would both be packaged as my-release-1.0.0.tgz")))
+	})
+
+	It("packages the migrations", func() {
+		migrations := tempDir()
+		err := ioutil.WriteFile(filepath.Join(migrations, "201901010000_rename.js"), []byte("exports.migrate = function(input) { return input; };"), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		productPath := filepath.Join(tempDir(), "product.pivotal")
+
+		command := commands.Build{
+			Paths:       []string{createReleaseTarball("my-release", "1.0.0")},
+			MergingFile: writeFile(buildOverlay),
+			Output:      productPath,
+			Migrations:  migrations,
+			Stdout:      gbytes.NewBuffer(),
+		}
+		err = command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		dir := tempDir()
+		err = archiver.NewZip().Unarchive(productPath, dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(filepath.Join(dir, "migrations", "v1", "201901010000_rename.js")).To(BeAnExistingFile())
+	})
+
+	It("fails when the resulting tile is not valid", func() {
+		stdout := gbytes.NewBuffer()
+		releasePath := createReleaseTarball("my-release", "1.0.0")
+
+		command := commands.Build{
+			Paths:  []string{releasePath},
+			Output: filepath.Join(tempDir(), "product.pivotal"),
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring("validation error(s)")))
+		Expect(stdout).To(gbytes.Say(`metadata/metadata.yml:\d+:1: icon_image: IconImage is a required field`))
+	})
+
+	It("requires releases to be tarballs", func() {
+		command := commands.Build{
+			Paths:  []string{tempDir()},
+			Output: filepath.Join(tempDir(), "product.pivotal"),
+			Stdout: gbytes.NewBuffer(),
+		}
+		err := command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring("must be a tarball")))
+	})
+})
+
+const buildOverlay = `
+name: example-tile
+product_version: "0.0.1-build.0"
+minimum_version_for_upgrade: "0.0.0"
+icon_image: some-image
+stemcell_criteria:
+  os: ubuntu-xenial
+  version: "456.30"
+`
+
+const buildSpec = `
+name: %s
+templates:
+  ctl.erb: bin/ctl
+properties:
+  some.property:
+    description: This property is important for something.
+    default: 1
+`
+
+func tempDir() string {
+	dir, err := ioutil.TempDir("", "")
+	Expect(err).NotTo(HaveOccurred())
+
+	return dir
+}
+
+func writeFile(contents string) string {
+	file, err := ioutil.TempFile("", "")
+	Expect(err).NotTo(HaveOccurred())
+
+	_, err = file.WriteString(contents)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = file.Close()
+	Expect(err).NotTo(HaveOccurred())
+
+	return file.Name()
+}
+
+func createReleaseTarball(name, version string, jobNames ...string) string {
+	return createReleaseTarballWithManifest(fmt.Sprintf("{name: %s, version: %s}", name, version), jobNames...)
+}
+
+func createCompiledReleaseTarball(name, version, stemcell string, jobNames ...string) string {
+	releaseMF := fmt.Sprintf(`
+name: %s
+version: %s
+compiled_packages:
+- name: %s-package
+  version: abc123
+  fingerprint: abc123
+  sha1: def456
+  stemcell: %s
+  dependencies: []
+`, name, version, name, stemcell)
+
+	return createReleaseTarballWithManifest(releaseMF, jobNames...)
+}
+
+func createReleaseTarballWithManifest(releaseMF string, jobNames ...string) string {
+	if len(jobNames) == 0 {
+		jobNames = []string{"some"}
+	}
+
+	buildDir := tempDir()
+
+	for _, jobName := range jobNames {
+		path := filepath.Join(buildDir, "jobs", jobName)
+		err := os.MkdirAll(path, os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(path, "job.MF"), []byte(fmt.Sprintf(buildSpec, jobName)), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archiver.Archive(
+			[]string{path},
+			filepath.Join(buildDir, "jobs", fmt.Sprintf("%s.tgz", jobName)),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = os.RemoveAll(path)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	err := ioutil.WriteFile(filepath.Join(buildDir, "release.MF"), []byte(releaseMF), os.ModePerm)
+	Expect(err).NotTo(HaveOccurred())
+
+	releasePath := filepath.Join(tempDir(), "release.tgz")
+	files, _ := filepath.Glob(filepath.Join(buildDir, "*"))
+
+	err = archiver.Archive(files, releasePath)
+	Expect(err).NotTo(HaveOccurred())
+
+	return releasePath
+}
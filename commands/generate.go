@@ -2,84 +2,239 @@ package commands
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 
-	"github.com/imdario/mergo"
 	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/merge"
 	"gopkg.in/yaml.v2"
 )
 
 type Generate struct {
-	Path        string `long:"path" required:"true" description:"path to the bosh release (source directory or tarball)"`
-	MergingFile string `long:"merge" description:"yaml file to merge results with"`
+	Paths                 []string          `long:"path" required:"true" description:"path to a bosh release, source directory or tarball (can be specified multiple times)"`
+	MergingFile           string            `long:"merge" description:"yaml file to merge results with, merging lists of named elements by name"`
+	MergeMode             string            `long:"merge-mode" default:"override" choice:"override" choice:"fill" description:"whether the merged file overrides the generated values, or only fills in missing ones"`
+	OpsFiles              []string          `long:"ops-file" description:"bosh ops-file of replace and remove operations to apply to the results (can be specified multiple times)"`
+	Links                 map[string]string `long:"cross-deployment-link" description:"consume a link from another product's deployment, as link:product (can be specified multiple times)"`
+	RulesFile             string            `long:"rules" description:"yaml file of rules to infer property blueprints with"`
+	FailOnUnresolvedLinks bool              `long:"fail-on-unresolved-links" description:"fail when a required link is not provided by any job"`
+	JobScoped             bool              `long:"job-scoped" description:"create the property blueprints on each job type, instead of one global property namespace"`
+	GroupBy               string            `long:"group-by" choice:"prefix" choice:"job" description:"group the properties into forms by name prefix or by job (default: job when job scoped, prefix otherwise)"`
+	GroupDepth            int               `long:"group-depth" default:"1" description:"number of name segments to group the properties by prefix with"`
+	GroupsFile            string            `long:"groups" description:"yaml file of forms and the regular expressions of the properties they group"`
+	PreviousFile          string            `long:"previous" description:"previously generated tile, to regenerate the edited tile from"`
+	EditedFile            string            `long:"edited" description:"hand edited copy of the previously generated tile, whose edits are carried forward"`
+	Stdout                io.Writer
+	Stderr                io.Writer
 }
 
 func (g Generate) Execute(_ []string) error {
-	contents, err := parseRelease(g.Path)
+	releases, err := parseReleases(g.Paths)
 	if err != nil {
 		return fmt.Errorf("tile creation failed: %s", err)
 	}
 
-	if g.MergingFile != "" {
-		contents, err = mergeWithContents(g.MergingFile, contents)
+	printStemcellWarnings(g.Stderr, releases)
+
+	options, err := tileOptions(generator.Options{
+		CrossDeploymentLinks: g.Links,
+		JobScoped:            g.JobScoped,
+		Grouping: generator.Grouping{
+			By:    g.GroupBy,
+			Depth: g.GroupDepth,
+		},
+	}, g.RulesFile, g.GroupsFile)
+	if err != nil {
+		return err
+	}
+
+	if !options.JobScoped {
+		printPropertyClashes(g.Stderr, releases)
+	}
+
+	printRuleMatches(g.Stderr, options, releases)
+
+	err = reportLinks(g.Stderr, options, releases, g.FailOnUnresolvedLinks)
+	if err != nil {
+		return err
+	}
+
+	contents, err := marshalTile(options, releases)
+	if err != nil {
+		return fmt.Errorf("tile creation failed: %s", err)
+	}
+
+	if g.PreviousFile != "" || g.EditedFile != "" {
+		contents, err = g.regenerate(contents)
 		if err != nil {
-			return fmt.Errorf("cannot merge file: %s", err)
+			return fmt.Errorf("cannot regenerate tile: %s", err)
 		}
 	}
 
-	fmt.Printf("%s", contents)
+	contents, err = applyOverlays(contents, g.MergingFile, merge.Mode(g.MergeMode), g.OpsFiles)
+	if err != nil {
+		return fmt.Errorf("cannot merge file: %s", err)
+	}
+
+	_, _ = fmt.Fprintf(g.Stdout, "%s", contents)
 
 	return nil
 }
 
-func mergeWithContents(file string, currentTileContents []byte) ([]byte, error) {
-	var currentTile map[string]interface{}
+func (g Generate) regenerate(contents []byte) ([]byte, error) {
+	if g.PreviousFile == "" || g.EditedFile == "" {
+		return nil, fmt.Errorf("both --previous and --edited are required")
+	}
 
-	err := yaml.Unmarshal(currentTileContents, &currentTile)
+	previous, err := ioutil.ReadFile(g.PreviousFile)
 	if err != nil {
 		return nil, err
 	}
 
-	var mergeFile map[string]interface{}
-
-	mergeFileContents, err := ioutil.ReadFile(file)
+	edited, err := ioutil.ReadFile(g.EditedFile)
 	if err != nil {
 		return nil, err
 	}
 
-	err = yaml.Unmarshal(mergeFileContents, &mergeFile)
+	contents, report, err := merge.ThreeWay(previous, edited, contents)
 	if err != nil {
 		return nil, err
 	}
 
-	err = mergo.Merge(&currentTile, mergeFile)
-	if err != nil {
-		return nil, err
+	for _, reference := range report.AddedProperties {
+		_, _ = fmt.Fprintf(g.Stderr, "added property %s\n", reference)
 	}
 
-	contents, err := yaml.Marshal(currentTile)
-	if err != nil {
-		return nil, err
+	for _, reference := range report.RemovedProperties {
+		_, _ = fmt.Fprintf(g.Stderr, "removed property %s\n", reference)
+	}
+
+	for _, path := range report.Conflicts {
+		_, _ = fmt.Fprintf(g.Stderr, "warning: %s was changed by both the edit and the release, keeping the edit\n", path)
 	}
 
 	return contents, nil
 }
 
-func parseRelease(releasePath string) ([]byte, error) {
-	specs, err := generator.ParseRelease(releasePath)
-	if err != nil {
-		return nil, err
+func applyOverlays(contents []byte, mergingFile string, mode merge.Mode, opsFiles []string) ([]byte, error) {
+	if mergingFile != "" {
+		overlay, err := ioutil.ReadFile(mergingFile)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err = merge.Overlay(contents, overlay, mode)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	tile, err := generator.Tile(specs)
-	if err != nil {
-		return nil, err
+	for _, opsFile := range opsFiles {
+		ops, err := ioutil.ReadFile(opsFile)
+		if err != nil {
+			return nil, err
+		}
+
+		contents, err = merge.OpsFile(contents, ops)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", opsFile, err)
+		}
+	}
+
+	return contents, nil
+}
+
+func parseReleases(releasePaths []string) ([]generator.BoshReleasePayload, error) {
+	var releases []generator.BoshReleasePayload
+
+	for _, releasePath := range releasePaths {
+		release, err := generator.ParseRelease(releasePath)
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, release)
 	}
 
-	contents, err := yaml.Marshal(tile)
+	return releases, nil
+}
+
+func marshalTile(options generator.Options, releases []generator.BoshReleasePayload) ([]byte, error) {
+	tile, err := generator.Tile(options, releases...)
 	if err != nil {
 		return nil, err
 	}
 
-	return contents, nil
+	return yaml.Marshal(tile)
+}
+
+func printStemcellWarnings(stderr io.Writer, releases []generator.BoshReleasePayload) {
+	_, warnings := generator.StemcellCriteria(releases...)
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(stderr, "warning: %s\n", warning)
+	}
+}
+
+func printPropertyClashes(stderr io.Writer, releases []generator.BoshReleasePayload) {
+	for _, clash := range generator.PropertyClashes(releases...) {
+		_, _ = fmt.Fprintf(stderr, "warning: %s, consider --job-scoped\n", clash)
+	}
+}
+
+func printRuleMatches(stderr io.Writer, options generator.Options, releases []generator.BoshReleasePayload) {
+	matches := generator.RuleMatches(options.Rules, releases...)
+	if len(matches) == 0 {
+		return
+	}
+
+	_, _ = fmt.Fprintln(stderr, "rules:")
+	for _, match := range matches {
+		_, _ = fmt.Fprintf(stderr, "  %s\n", match)
+	}
+}
+
+func tileOptions(options generator.Options, rulesFile, groupsFile string) (generator.Options, error) {
+	if rulesFile != "" {
+		rules, err := generator.LoadRules(rulesFile)
+		if err != nil {
+			return options, err
+		}
+
+		options.Rules = rules
+	}
+
+	if groupsFile != "" {
+		groups, err := generator.LoadGroups(groupsFile)
+		if err != nil {
+			return options, err
+		}
+
+		options.Grouping.Groups = groups
+	}
+
+	return options, nil
+}
+
+func reportLinks(stderr io.Writer, options generator.Options, releases []generator.BoshReleasePayload, failOnUnresolved bool) error {
+	resolutions := generator.LinkResolutions(options, releases...)
+	if len(resolutions) == 0 {
+		return nil
+	}
+
+	unresolved := 0
+
+	_, _ = fmt.Fprintln(stderr, "links:")
+	for _, resolution := range resolutions {
+		_, _ = fmt.Fprintf(stderr, "  %s\n", resolution)
+
+		if resolution.Status == generator.LinkRequiredUnresolved {
+			unresolved++
+		}
+	}
+
+	if failOnUnresolved && unresolved > 0 {
+		return fmt.Errorf("tile has %d unresolved required link(s)", unresolved)
+	}
+
+	return nil
 }
@@ -0,0 +1,98 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/jtarchie/tile-builder/metadata"
+	"github.com/jtarchie/tile-builder/migrations"
+)
+
+type GenerateMigrations struct {
+	From    string            `long:"from" required:"true" description:"previous version of the tile, as a .pivotal or a metadata file"`
+	To      string            `long:"to" required:"true" description:"current version of the tile, as a .pivotal or a metadata file"`
+	Renames map[string]string `long:"rename" description:"property blueprint renamed between the versions, as old-reference:new-reference (can be specified multiple times)"`
+	Output  string            `long:"output" default:"migrations/v1" description:"directory to write the migration to"`
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+var nonVersion = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+func (g GenerateMigrations) Execute(_ []string) error {
+	before, err := loadPayload(g.From)
+	if err != nil {
+		return err
+	}
+
+	after, err := loadPayload(g.To)
+	if err != nil {
+		return err
+	}
+
+	changes, err := migrations.Compare(before, after, g.Renames)
+	if err != nil {
+		return fmt.Errorf("could not compare tiles: %s", err)
+	}
+
+	for _, change := range changes {
+		switch {
+		case change.Kind == migrations.Suggested:
+			_, _ = fmt.Fprintf(g.Stderr, "suggestion: %s, pass --rename %s:%s to migrate its value\n", change, change.From, change.To)
+		case change.NeedsAttention():
+			_, _ = fmt.Fprintf(g.Stderr, "warning: %s and needs manual attention\n", change)
+		default:
+			_, _ = fmt.Fprintf(g.Stdout, "%s\n", change)
+		}
+	}
+
+	script := migrations.Script(changes)
+	if script == nil {
+		_, _ = fmt.Fprintln(g.Stdout, "no migration needed")
+		return nil
+	}
+
+	err = os.MkdirAll(g.Output, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("could not create %s: %s", g.Output, err)
+	}
+
+	path := filepath.Join(g.Output, fmt.Sprintf(
+		"%s_migrate_%s_to_%s.js",
+		time.Now().UTC().Format("200601021504"),
+		nonVersion.ReplaceAllString(before.ProductVersion, "_"),
+		nonVersion.ReplaceAllString(after.ProductVersion, "_"),
+	))
+
+	err = ioutil.WriteFile(path, script, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write migration %s: %s", path, err)
+	}
+
+	_, _ = fmt.Fprintf(g.Stdout, "wrote %s\n", path)
+
+	return nil
+}
+
+func loadPayload(path string) (metadata.Payload, error) {
+	if filepath.Ext(path) == ".pivotal" {
+		return metadata.FromTile(path, false)
+	}
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return metadata.Payload{}, fmt.Errorf("could not read %s: %s", path, err)
+	}
+
+	payload, err := metadata.Parse(contents, false)
+	if err != nil {
+		return metadata.Payload{}, fmt.Errorf("could not unmarshal %s: %s", path, err)
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,98 @@
+package commands_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/jtarchie/tile-builder/commands"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("GenerateMigrations", func() {
+	const before = `
+product_version: 1.0.0
+property_blueprints:
+- {name: port, type: integer, default: 8080}
+- {name: legacy, type: string}
+`
+	const after = `
+product_version: 1.1.0
+property_blueprints:
+- {name: listen_port, type: integer, default: 8080}
+`
+
+	It("writes a migration for the renamed property blueprints and flags the removed ones", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+		output := filepath.Join(tempDir(), "migrations", "v1")
+
+		command := commands.GenerateMigrations{
+			From:    writeFile(before),
+			To:      writeFile(after),
+			Renames: map[string]string{".properties.port": ".properties.listen_port"},
+			Output:  output,
+			Stdout:  stdout,
+			Stderr:  stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(gbytes.Say(`.properties.port was renamed to .properties.listen_port`))
+		Expect(stdout).To(gbytes.Say(`wrote .*_migrate_1_0_0_to_1_1_0.js`))
+		Expect(stderr).To(gbytes.Say(`warning: .properties.legacy was removed and needs manual attention`))
+
+		paths, err := filepath.Glob(filepath.Join(output, "*_migrate_1_0_0_to_1_1_0.js"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+
+		contents, err := ioutil.ReadFile(paths[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("exports.migrate = function(input) {"))
+		Expect(string(contents)).To(ContainSubstring("properties['.properties.listen_port'] = properties['.properties.port'];"))
+	})
+
+	It("suggests renames and writes the notes of the removed property blueprints", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+		output := filepath.Join(tempDir(), "migrations", "v1")
+
+		command := commands.GenerateMigrations{
+			From:   writeFile(before),
+			To:     writeFile(after),
+			Output: output,
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(gbytes.Say(`warning: .properties.legacy was removed and needs manual attention`))
+		Expect(stderr).To(gbytes.Say(`warning: .properties.port was removed and needs manual attention`))
+		Expect(stderr).To(gbytes.Say(`suggestion: .properties.port may have been renamed to .properties.listen_port, pass --rename .properties.port:.properties.listen_port to migrate its value`))
+		Expect(stdout).To(gbytes.Say(`wrote .*_migrate_1_0_0_to_1_1_0.js`))
+
+		paths, err := filepath.Glob(filepath.Join(output, "*_migrate_1_0_0_to_1_1_0.js"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+
+		contents, err := ioutil.ReadFile(paths[0])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("// TODO: .properties.port was removed"))
+		Expect(string(contents)).NotTo(ContainSubstring("properties['.properties.listen_port']"))
+	})
+
+	It("writes nothing when nothing changed", func() {
+		stdout := gbytes.NewBuffer()
+		output := filepath.Join(tempDir(), "migrations")
+
+		command := commands.GenerateMigrations{
+			From:   writeFile(before),
+			To:     writeFile(before),
+			Output: output,
+			Stdout: stdout,
+			Stderr: gbytes.NewBuffer(),
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(gbytes.Say("no migration needed"))
+		Expect(output).NotTo(BeADirectory())
+	})
+})
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/jtarchie/tile-builder/configuration"
+)
+
+type GenerateProductConfig struct {
+	Tile   TileArgs `group:"tile" namespace:"tile" env-namespace:"TILE"`
+	Pivnet pivnet   `group:"pivnet" namespace:"pivnet" env-namespace:"PIVNET"`
+	Strict bool     `long:"strict" description:"use strict unmarshaling for the tile"`
+	Stdout io.Writer
+}
+
+func (g GenerateProductConfig) Execute(_ []string) error {
+	payload, err := loadMetadataForTile(g.Tile, g.Pivnet, g.Strict)
+	if err != nil {
+		return err
+	}
+
+	contents, err := configuration.Template(payload)
+	if err != nil {
+		return fmt.Errorf("could not generate product config: %s", err)
+	}
+
+	_, err = g.Stdout.Write(contents)
+	return err
+}
@@ -0,0 +1,43 @@
+package commands_test
+
+import (
+	"github.com/jtarchie/tile-builder/commands"
+	"github.com/jtarchie/tile-builder/configuration"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("GenerateProductConfig", func() {
+	It("writes a product config for the tile", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.GenerateProductConfig{
+			Tile: commands.TileArgs{
+				Path: createProductFile(metadata.Payload{
+					Name: "example",
+					PropertyBlueprints: []metadata.PropertyBlueprint{
+						{Name: "port", Type: "port", Configurable: true, Default: 8080},
+					},
+					JobTypes: []metadata.JobType{
+						{
+							Name:               "router",
+							InstanceDefinition: metadata.InstanceDefinition{Default: 2},
+						},
+					},
+				}),
+			},
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var product configuration.Product
+		err = yaml.UnmarshalStrict(stdout.Contents(), &product)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(product.Name).To(Equal("example"))
+		Expect(product.ProductProperties).To(HaveKeyWithValue(".properties.port", configuration.Property{Value: 8080}))
+		Expect(product.ResourceConfig["router"].Instances).To(Equal(2))
+	})
+})
@@ -0,0 +1,269 @@
+package commands_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/jtarchie/tile-builder/commands"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Generate", func() {
+	It("generates metadata from multiple releases", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths: []string{
+				createReleaseTarball("my-release", "1.0.0", "web"),
+				createReleaseTarball("other-release", "2.0.0", "worker"),
+			},
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr.Contents()).To(BeEmpty())
+
+		var payload metadata.Payload
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.Releases).To(HaveLen(2))
+		Expect(payload.JobTypes[0].Templates[0].Release).To(Equal("my-release"))
+		Expect(payload.JobTypes[1].Templates[0].Release).To(Equal("other-release"))
+	})
+
+	It("pins the stemcell of compiled releases and warns when they differ", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths: []string{
+				createCompiledReleaseTarball("my-release", "1.0.0", "ubuntu-xenial/621.74", "web"),
+				createCompiledReleaseTarball("other-release", "2.0.0", "ubuntu-xenial/456.30", "worker"),
+			},
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(gbytes.Say("warning: 1 package\\(s\\) of release other-release are compiled against stemcell ubuntu-xenial/456.30, but the tile is pinned to stemcell ubuntu-xenial/621.74"))
+
+		var payload metadata.Payload
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.StemcellCriteria.OS).To(Equal("ubuntu-xenial"))
+		Expect(payload.StemcellCriteria.Version).To(Equal("621.74"))
+	})
+
+	It("reports the resolution of links and can fail on unresolved ones", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths:  []string{createReleaseDir("my-release", "0.0.1", "web", consumingSpec)},
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(gbytes.Say("links:"))
+		Expect(stderr).To(gbytes.Say("  web consumes database \\(database\\): required-unresolved"))
+
+		command.FailOnUnresolvedLinks = true
+		err = command.Execute(nil)
+		Expect(err).To(MatchError("tile has 1 unresolved required link(s)"))
+	})
+
+	It("warns about properties defined differently by jobs, unless they are job scoped", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+
+		dir := createReleaseDir("my-release", "0.0.1", "web", "{name: web, properties: {port: {default: 8080}}}")
+		err := os.MkdirAll(filepath.Join(dir, "jobs", "worker"), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+		err = ioutil.WriteFile(filepath.Join(dir, "jobs", "worker", "spec"), []byte("{name: worker, properties: {port: {default: 9090}}}"), os.ModePerm)
+		Expect(err).NotTo(HaveOccurred())
+
+		command := commands.Generate{
+			Paths:  []string{dir},
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		err = command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(gbytes.Say("warning: property port of release my-release is defined differently by jobs web, worker, consider --job-scoped"))
+
+		stdout, stderr = gbytes.NewBuffer(), gbytes.NewBuffer()
+		command.Stdout, command.Stderr = stdout, stderr
+		command.JobScoped = true
+		err = command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr.Contents()).To(BeEmpty())
+
+		var payload metadata.Payload
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.PropertyBlueprints).To(BeEmpty())
+		Expect(payload.JobTypes[0].PropertyBlueprints[0].Default).To(Equal(8080))
+		Expect(payload.JobTypes[1].PropertyBlueprints[0].Default).To(Equal(9090))
+		Expect(payload.JobTypes[1].Manifest).To(MatchYAML("port: ((.worker.port.value))"))
+	})
+
+	It("groups the properties into forms from a groups file", func() {
+		stdout := gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths:      []string{createReleaseTarball("my-release", "1.0.0", "web")},
+			GroupsFile: writeFile("[{name: Important Settings, properties: ['^some\\.']}]"),
+			Stdout:     stdout,
+			Stderr:     gbytes.NewBuffer(),
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var payload metadata.Payload
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.FormTypes).To(HaveLen(1))
+		Expect(payload.FormTypes[0].Name).To(Equal("important_settings"))
+		Expect(payload.FormTypes[0].PropertyInputs[0].Reference).To(Equal(".properties.some__property"))
+	})
+
+	It("reports the rule matching each property from a rules file", func() {
+		stderr := gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths:     []string{createReleaseTarball("my-release", "1.0.0", "web")},
+			RulesFile: writeFile("[{name: settings, property: '^some\\.', type: text}]"),
+			Stdout:    gbytes.NewBuffer(),
+			Stderr:    stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(gbytes.Say("rules:"))
+		Expect(stderr).To(gbytes.Say("  some.property of web matched rule settings"))
+
+		command.RulesFile = writeFile("[{name: settings, property: '^some\\.', type: txt}]")
+		err = command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring(`has an unknown property blueprint type "txt"`)))
+	})
+
+	It("regenerates a hand edited tile", func() {
+		stdout := gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths:  []string{createReleaseTarball("my-release", "1.0.0", "web")},
+			Stdout: stdout,
+			Stderr: gbytes.NewBuffer(),
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		previous := stdout.Contents()
+
+		var edited map[string]interface{}
+		err = yaml.Unmarshal(previous, &edited)
+		Expect(err).NotTo(HaveOccurred())
+		edited["label"] = "My Tile"
+
+		contents, err := yaml.Marshal(edited)
+		Expect(err).NotTo(HaveOccurred())
+
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+		command = commands.Generate{
+			Paths:        []string{createReleaseDir("my-release", "2.0.0", "web", "{name: web, properties: {some.other: {default: 2}}}")},
+			PreviousFile: writeFile(string(previous)),
+			EditedFile:   writeFile(string(contents)),
+			Stdout:       stdout,
+			Stderr:       stderr,
+		}
+		err = command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stderr).To(gbytes.Say("added property .properties.some__other"))
+		Expect(stderr).To(gbytes.Say("removed property .properties.some__property"))
+
+		var payload metadata.Payload
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.Label).To(Equal("My Tile"))
+		Expect(payload.Releases[0].Version).To(Equal("2.0.0"))
+		Expect(payload.PropertyBlueprints).To(HaveLen(1))
+		Expect(payload.PropertyBlueprints[0].Name).To(Equal("some__other"))
+
+		command.EditedFile = ""
+		err = command.Execute(nil)
+		Expect(err).To(MatchError("cannot regenerate tile: both --previous and --edited are required"))
+	})
+
+	It("merges an overlay and ops-files into the results", func() {
+		stdout := gbytes.NewBuffer()
+
+		command := commands.Generate{
+			Paths:       []string{createReleaseTarball("my-release", "1.0.0", "web", "worker")},
+			MergingFile: writeFile("{label: My Tile, job_types: [{name: web, resource_label: Web Server}, {name: worker, _delete: true}]}"),
+			OpsFiles:    []string{writeFile("[{type: replace, path: /job_types/name=web/max_in_flight, value: 2}]")},
+			Stdout:      stdout,
+			Stderr:      gbytes.NewBuffer(),
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var payload metadata.Payload
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.Label).To(Equal("My Tile"))
+		Expect(payload.JobTypes).To(HaveLen(1))
+		Expect(payload.JobTypes[0].ResourceLabel).To(Equal("Web Server"))
+		Expect(payload.JobTypes[0].MaxInFlight).To(Equal(2))
+		Expect(payload.JobTypes[0].Templates[0].Release).To(Equal("my-release"))
+
+		stdout = gbytes.NewBuffer()
+		command.Stdout = stdout
+		command.MergeMode = "fill"
+		command.OpsFiles = nil
+		err = command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		payload = metadata.Payload{}
+		err = yaml.Unmarshal(stdout.Contents(), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(payload.Label).To(Equal("My Tile"))
+		Expect(payload.JobTypes[0].ResourceLabel).To(Equal("Web"))
+	})
+})
+
+const consumingSpec = `
+name: web
+consumes:
+- name: database
+  type: database
+`
+
+func createReleaseDir(name, version, jobName, spec string) string {
+	dir := tempDir()
+
+	err := os.MkdirAll(filepath.Join(dir, "jobs", jobName), os.ModePerm)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = ioutil.WriteFile(filepath.Join(dir, "jobs", jobName, "spec"), []byte(spec), os.ModePerm)
+	Expect(err).NotTo(HaveOccurred())
+
+	err = os.MkdirAll(filepath.Join(dir, "releases", name), os.ModePerm)
+	Expect(err).NotTo(HaveOccurred())
+
+	releaseYAML := fmt.Sprintf("{name: %s, version: %s}", name, version)
+	err = ioutil.WriteFile(filepath.Join(dir, "releases", name, fmt.Sprintf("%s-%s.yml", name, version)), []byte(releaseYAML), os.ModePerm)
+	Expect(err).NotTo(HaveOccurred())
+
+	return dir
+}
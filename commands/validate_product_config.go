@@ -2,14 +2,22 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+
 	"github.com/jtarchie/tile-builder/configuration"
+	"github.com/jtarchie/tile-builder/metadata"
 )
 
 type ValidateProductConfig struct {
-	Config string   `long:"config" description:"config file of the product" require:"true"`
+	Config string   `long:"config" description:"config file of the product" required:"true"`
 	Tile   TileArgs `group:"tile" namespace:"tile" env-namespace:"TILE"`
 	Pivnet pivnet   `group:"pivnet" namespace:"pivnet" env-namespace:"PIVNET"`
 	Strict bool     `long:"strict" description:"use strict unmarshaling for the tile"`
+	Stdout io.Writer
+	Stderr io.Writer
 }
 
 func (p ValidateProductConfig) Execute(_ []string) error {
@@ -23,18 +31,186 @@ func (p ValidateProductConfig) Execute(_ []string) error {
 		return err
 	}
 
-	for reference, pi := range configPayload.ProductProperties {
-		pb, found := metadataPayload.FindPropertyBlueprintFromPropertyInput(reference)
+	networkProblems, warnings := validateNetworkProperties(metadataPayload, configPayload)
+
+	var problems []string
+	problems = append(problems, validateProductProperties(metadataPayload, configPayload)...)
+	problems = append(problems, networkProblems...)
+	problems = append(problems, validateResourceConfig(metadataPayload, configPayload)...)
+
+	for _, warning := range warnings {
+		_, _ = fmt.Fprintf(p.Stderr, "warning: %s\n", warning)
+	}
 
+	for _, problem := range problems {
+		_, _ = fmt.Fprintln(p.Stdout, problem)
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("product config %s has %d problem(s)", p.Config, len(problems))
+	}
+
+	return nil
+}
+
+func validateProductProperties(metadataPayload metadata.Payload, configPayload configuration.Product) []string {
+	var problems []string
+
+	references := []string{}
+	for reference := range configPayload.ProductProperties {
+		references = append(references, reference)
+	}
+
+	sort.Strings(references)
+
+	for _, reference := range references {
+		pb, found := metadataPayload.FindPropertyBlueprintFromPropertyInput(reference)
 		if !found {
-			return fmt.Errorf("cannot determine lookup path of property '%s', expected `.properties` or `.job-name`", reference)
+			problems = append(problems, fmt.Sprintf("cannot determine lookup path of property '%s', expected `.properties` or `.job-name`", reference))
+			continue
+		}
+
+		if !pb.Configurable {
+			problems = append(problems, fmt.Sprintf("property '%s' is not configurable", reference))
+			continue
 		}
 
-		err = pb.ValidateValue(pi.Value)
+		err := pb.ValidateValue(configPayload.ProductProperties[reference].Value)
 		if err != nil {
-			return fmt.Errorf("property '%s' value is incorrect: %s", reference, err)
+			problems = append(problems, fmt.Sprintf("property '%s' value is incorrect: %s", reference, err))
 		}
 	}
 
-	return nil
+	return problems
+}
+
+// validateNetworkProperties returns the problems of the network properties,
+// and warnings for the ones that are set but unused.
+func validateNetworkProperties(metadataPayload metadata.Payload, configPayload configuration.Product) ([]string, []string) {
+	var problems, warnings []string
+
+	network := configPayload.NetworkProperties
+
+	// a product config without network properties leaves them as configured
+	if reflect.DeepEqual(network, configuration.NetworkProperties{}) {
+		return nil, nil
+	}
+
+	var singleAZ, multiAZ bool
+	for _, jobType := range metadataPayload.JobTypes {
+		if jobType.SingleAZOnly {
+			singleAZ = true
+		} else {
+			multiAZ = true
+		}
+	}
+
+	if (singleAZ || multiAZ) && network.Network.Name == "" {
+		problems = append(problems, "network-properties: 'network.name' is required as the tile has jobs")
+	}
+
+	if singleAZ && network.SingletonAvailabilityZone.Name == "" {
+		problems = append(problems, "network-properties: 'singleton_availability_zone.name' is required as the tile has single AZ jobs")
+	}
+
+	if multiAZ && len(network.OtherAvailabilityZones) == 0 {
+		problems = append(problems, "network-properties: 'other_availability_zones' is required as the tile has multi AZ jobs")
+	}
+
+	for index, az := range network.OtherAvailabilityZones {
+		if az.Name == "" {
+			problems = append(problems, fmt.Sprintf("network-properties: 'other_availability_zones[%d].name' is required", index))
+		}
+	}
+
+	needsServiceNetwork := metadataPayload.RequiresServiceNetwork()
+	if needsServiceNetwork && network.ServiceNetwork.Name == "" {
+		problems = append(problems, "network-properties: 'service_network.name' is required as the tile deploys on-demand services")
+	}
+
+	if !needsServiceNetwork && network.ServiceNetwork.Name != "" {
+		warnings = append(warnings, "network-properties: 'service_network' is set, but the tile does not deploy on-demand services")
+	}
+
+	return problems, warnings
+}
+
+func validateResourceConfig(metadataPayload metadata.Payload, configPayload configuration.Product) []string {
+	var problems []string
+
+	jobTypes := map[string]metadata.JobType{}
+	for _, jobType := range metadataPayload.JobTypes {
+		jobTypes[jobType.Name] = jobType
+	}
+
+	names := []string{}
+	for name := range configPayload.ResourceConfig {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		config := configPayload.ResourceConfig[name]
+
+		if instances, ok := config.Instances.(int); ok && instances < 0 {
+			problems = append(problems, fmt.Sprintf("resource-config '%s' instances is incorrect: expected a non-negative number, got %d", name, instances))
+			config.Instances = nil
+		}
+
+		jobType, found := jobTypes[name]
+		if !found {
+			problems = append(problems, fmt.Sprintf("resource-config '%s' does not match a job type", name))
+			continue
+		}
+
+		if config.Instances != nil && config.Instances != "automatic" {
+			err := validateInstances(jobType.InstanceDefinition, config.Instances)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("resource-config '%s' instances is incorrect: %s", name, err))
+			}
+		}
+
+		sizeMB := config.PersistentDisk.SizeMB
+		if sizeMB != "" && sizeMB != "automatic" {
+			err := validatePersistentDisk(jobType, sizeMB)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("resource-config '%s' persistent_disk is incorrect: %s", name, err))
+			}
+		}
+	}
+
+	return problems
+}
+
+func validateInstances(definition metadata.InstanceDefinition, value interface{}) error {
+	instances, ok := value.(int)
+	if !ok {
+		return fmt.Errorf("expected an integer or 'automatic', got %#v", value)
+	}
+
+	if !definition.Configurable && instances != definition.Default {
+		return fmt.Errorf("instances are not configurable, expected %d", definition.Default)
+	}
+
+	return definition.Constraints.Validate(instances)
+}
+
+func validatePersistentDisk(jobType metadata.JobType, sizeMB string) error {
+	size, err := strconv.Atoi(sizeMB)
+	if err != nil {
+		return fmt.Errorf("expected a size in MB or 'automatic', got %q", sizeMB)
+	}
+
+	for _, definition := range jobType.ResourceDefinitions {
+		if definition.Name == "persistent_disk" {
+			if !definition.Configurable {
+				return fmt.Errorf("persistent disk is not configurable")
+			}
+
+			return definition.Constraints.Validate(size)
+		}
+	}
+
+	return fmt.Errorf("job type does not have a persistent disk")
 }
@@ -0,0 +1,172 @@
+package commands_test
+
+import (
+	"github.com/jtarchie/tile-builder/commands"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("ValidateProductConfig", func() {
+	var productPath string
+
+	BeforeEach(func() {
+		productPath = createProductFile(metadata.Payload{
+			PropertyBlueprints: []metadata.PropertyBlueprint{
+				{Name: "port", Type: "port", Configurable: true},
+				{Name: "fixed", Type: "string"},
+			},
+			JobTypes: []metadata.JobType{
+				{
+					Name:         "router",
+					SingleAZOnly: false,
+					InstanceDefinition: metadata.InstanceDefinition{
+						Configurable: true,
+						Default:      1,
+						Constraints:  metadata.Constraints{Min: 1, MaxOnlyBeOddOrZero: true},
+					},
+					ResourceDefinitions: []metadata.ResourceDefinition{
+						{
+							Name:         "persistent_disk",
+							Configurable: true,
+							Constraints:  metadata.Constraints{Min: 10240},
+						},
+					},
+				},
+				{
+					Name:         "singleton",
+					SingleAZOnly: true,
+					InstanceDefinition: metadata.InstanceDefinition{
+						Default: 1,
+					},
+				},
+			},
+		})
+	})
+
+	It("succeeds with a valid config", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateProductConfig{
+			Config: writeFile(`
+name: example
+network-properties:
+  network: {name: some-network}
+  singleton_availability_zone: {name: az1}
+  other_availability_zones: [{name: az1}, {name: az2}]
+product-properties:
+  .properties.port: {value: 8080}
+resource-config:
+  router:
+    instances: 3
+    persistent_disk: {size_mb: "20480"}
+  singleton:
+    instances: automatic
+`),
+			Tile:   commands.TileArgs{Path: productPath},
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.Contents()).To(BeEmpty())
+	})
+
+	It("reports every problem in the config", func() {
+		stdout, stderr := gbytes.NewBuffer(), gbytes.NewBuffer()
+		command := commands.ValidateProductConfig{
+			Config: writeFile(`
+name: example
+network-properties:
+  service_network: {name: services}
+product-properties:
+  .properties.port: {value: 0}
+  .properties.fixed: {value: something}
+  .properties.missing: {value: something}
+resource-config:
+  router:
+    instances: 2
+    persistent_disk: {size_mb: "1024"}
+  singleton:
+    instances: 2
+    persistent_disk: {size_mb: "10240"}
+  unknown:
+    instances: 1
+`),
+			Tile:   commands.TileArgs{Path: productPath},
+			Stdout: stdout,
+			Stderr: stderr,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring("has 11 problem(s)")))
+		Expect(stderr).To(gbytes.Say(`warning: network-properties: 'service_network' is set, but the tile does not deploy on-demand services`))
+
+		Expect(stdout).To(gbytes.Say(`property '.properties.fixed' is not configurable`))
+		Expect(stdout).To(gbytes.Say(`cannot determine lookup path of property '.properties.missing'`))
+		Expect(stdout).To(gbytes.Say(`property '.properties.port' value is incorrect: expected a port between 1 and 65535, got 0`))
+		Expect(stdout).To(gbytes.Say(`network-properties: 'network.name' is required`))
+		Expect(stdout).To(gbytes.Say(`network-properties: 'singleton_availability_zone.name' is required`))
+		Expect(stdout).To(gbytes.Say(`network-properties: 'other_availability_zones' is required`))
+		Expect(stdout).To(gbytes.Say(`resource-config 'router' instances is incorrect: expected an odd number or zero, got 2`))
+		Expect(stdout).To(gbytes.Say(`resource-config 'router' persistent_disk is incorrect: expected at least 10240, got 1024`))
+		Expect(stdout).To(gbytes.Say(`resource-config 'singleton' instances is incorrect: instances are not configurable, expected 1`))
+		Expect(stdout).To(gbytes.Say(`resource-config 'singleton' persistent_disk is incorrect: job type does not have a persistent disk`))
+		Expect(stdout).To(gbytes.Say(`resource-config 'unknown' does not match a job type`))
+	})
+
+	It("does not require network properties when the config has none", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateProductConfig{
+			Config: writeFile(`
+name: example
+product-properties:
+  .properties.port: {value: 8080}
+`),
+			Tile:   commands.TileArgs{Path: productPath},
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.Contents()).To(BeEmpty())
+	})
+
+	It("rejects negative instances, whatever the constraints of the job type", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateProductConfig{
+			Config: writeFile(`
+name: example
+resource-config:
+  worker:
+    instances: -1
+`),
+			Tile: commands.TileArgs{Path: createProductFile(metadata.Payload{
+				JobTypes: []metadata.JobType{
+					{Name: "worker", InstanceDefinition: metadata.InstanceDefinition{Configurable: true, Default: 1}},
+				},
+			})},
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring("has 1 problem(s)")))
+		Expect(stdout).To(gbytes.Say(`resource-config 'worker' instances is incorrect: expected a non-negative number, got -1`))
+	})
+
+	It("requires the service network when the tile is a service broker", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateProductConfig{
+			Config: writeFile(`
+name: example
+network-properties:
+  network: {name: some-network}
+  singleton_availability_zone: {name: az1}
+  other_availability_zones: [{name: az1}]
+`),
+			Tile: commands.TileArgs{Path: createProductFile(metadata.Payload{
+				ServiceBroker: true,
+			})},
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(HaveOccurred())
+		Expect(stdout).To(gbytes.Say(`network-properties: 'service_network.name' is required`))
+	})
+})
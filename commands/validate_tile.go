@@ -3,7 +3,6 @@ package commands
 import (
 	"fmt"
 	"io"
-	"sort"
 
 	"github.com/jtarchie/tile-builder/metadata"
 )
@@ -22,48 +21,75 @@ type ValidateTile struct {
 	Tile   TileArgs `group:"tile" namespace:"tile" env-namespace:"TILE"`
 	Pivnet pivnet   `group:"pivnet" namespace:"pivnet" env-namespace:"PIVNET"`
 	Strict bool     `long:"strict" description:"use strict unmarshaling for the tile"`
+	Output string   `long:"output" default:"text" choice:"text" choice:"json" choice:"junit" description:"format of the validation errors"`
 	Stdout io.Writer
 }
 
 func (p ValidateTile) Execute(_ []string) error {
-	payload, err := loadMetadataForTile(p.Tile, p.Pivnet, p.Strict)
+	source, err := loadSourceForTile(p.Tile, p.Pivnet)
 	if err != nil {
 		return err
 	}
 
-	validations, err := payload.Validate()
+	payload, err := metadata.Parse(source.Contents, p.Strict)
+
+	// unknown keys are reported with the other validations
+	unknownKeys, unknown := err.(metadata.UnknownKeysError)
+	if unknown {
+		payload, err = metadata.Parse(source.Contents, false)
+	}
+
 	if err != nil {
-		return fmt.Errorf("could not determine validations on tile: %s", err)
+		return fmt.Errorf("could not unmarshal %s: %s", source.Filename, err)
 	}
 
-	keys := []string{}
-	for field := range validations {
-		keys = append(keys, field)
+	validations, err := payload.ValidateWithSource(source.Contents)
+	if err != nil {
+		return fmt.Errorf("could not determine validations on tile: %s", err)
 	}
 
-	sort.Strings(keys)
+	validations = append(unknownKeys.ValidationErrors(), validations...)
 
-	for _, key := range keys {
-		_, _ = fmt.Fprintf(p.Stdout, "%s: %s\n", key, validations[key])
+	err = writeValidations(p.Stdout, p.Output, source, validations)
+	if err != nil {
+		return fmt.Errorf("could not write validations: %s", err)
+	}
+
+	if len(validations) > 0 {
+		return fmt.Errorf("tile has %d validation error(s)", len(validations))
 	}
 
 	return nil
 }
 
-func loadMetadataForTile(t TileArgs, p pivnet, strict bool) (metadata.Payload, error) {
+func loadSourceForTile(t TileArgs, p pivnet) (metadata.Source, error) {
 	if t.Path != "" {
-		payload, err := metadata.FromTile(t.Path, false)
+		source, err := metadata.ReadTile(t.Path)
 		if err != nil {
-			return metadata.Payload{}, fmt.Errorf("could not load metadata from tile: %s", err)
+			return metadata.Source{}, fmt.Errorf("could not load metadata from tile: %s", err)
 		}
-		return payload, nil
+		return source, nil
 	} else if p.Token != "" {
-		payload, err := metadata.FromPivnet(p.Token, p.Slug, p.Version, false)
+		source, err := metadata.ReadPivnet(p.Token, p.Slug, p.Version)
 		if err != nil {
-			return metadata.Payload{}, fmt.Errorf("could not load metadata from pivnet: %s", err)
+			return metadata.Source{}, fmt.Errorf("could not load metadata from pivnet: %s", err)
 		}
-		return payload, nil
+		return source, nil
+	}
+
+	return metadata.Source{}, fmt.Errorf("could not determine tile or pivnet metadata")
+}
+
+func loadMetadataForTile(t TileArgs, p pivnet, strict bool) (metadata.Payload, error) {
+	source, err := loadSourceForTile(t, p)
+	if err != nil {
+		return metadata.Payload{}, err
+	}
+
+	payload, err := metadata.Parse(source.Contents, strict)
+	if err != nil {
+		return metadata.Payload{}, fmt.Errorf("could not load metadata from %s: %s", source.Filename, err)
 	}
 
-	return metadata.Payload{}, fmt.Errorf("could not determine tile or pivnet metadata")
+	return payload, nil
 }
@@ -1,6 +1,7 @@
 package commands_test
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -26,12 +27,140 @@ var _ = Describe("ValidateTile", func() {
 			Stdout: stdout,
 		}
 		err := command.Execute(nil)
-		Expect(err).ToNot(HaveOccurred())
-		Expect(stdout).To(gbytes.Say("Payload.Name: Name is a required field"))
+		Expect(err).To(MatchError("tile has 6 validation error(s)"))
+		Expect(stdout).To(gbytes.Say(`metadata/metadata.yml:10:1: name: Name is a required field`))
+	})
+
+	It("succeeds when there are no validation errors", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateTile{
+			Tile: commands.TileArgs{
+				Path: createProductFile(validPayload()),
+			},
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.Contents()).To(BeEmpty())
+	})
+
+	It("writes validation errors as JSON", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateTile{
+			Tile: commands.TileArgs{
+				Path: createProductFile(metadata.Payload{}),
+			},
+			Output: "json",
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(HaveOccurred())
+
+		var validations []map[string]interface{}
+		Expect(json.Unmarshal(stdout.Contents(), &validations)).To(Succeed())
+		Expect(validations).To(HaveLen(6))
+		Expect(validations).To(ContainElement(map[string]interface{}{
+			"file":    "metadata/metadata.yml",
+			"line":    10.0,
+			"column":  1.0,
+			"path":    "name",
+			"field":   "Payload.Name",
+			"message": "Name is a required field",
+		}))
+	})
+
+	It("writes validation errors as JUnit XML", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateTile{
+			Tile: commands.TileArgs{
+				Path: createProductFile(metadata.Payload{}),
+			},
+			Output: "junit",
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(HaveOccurred())
+
+		Expect(stdout).To(gbytes.Say(`<testsuite name="validate-tile" tests="6" failures="6">`))
+		Expect(stdout).To(gbytes.Say(`<testcase name="name" classname="metadata/metadata.yml">`))
+		Expect(stdout).To(gbytes.Say(`<failure message="Name is a required field">metadata/metadata.yml:10:1: name: Name is a required field</failure>`))
+	})
+
+	It("writes a passing JUnit test case when there are no validation errors", func() {
+		stdout := gbytes.NewBuffer()
+		command := commands.ValidateTile{
+			Tile: commands.TileArgs{
+				Path: createProductFile(validPayload()),
+			},
+			Output: "junit",
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout).To(gbytes.Say(`<testsuite name="validate-tile" tests="1" failures="0">`))
+		Expect(stdout).To(gbytes.Say(`<testcase name="metadata" classname="metadata/metadata.yml"></testcase>`))
+	})
+
+	It("reports unknown keys when strict", func() {
+		stdout := gbytes.NewBuffer()
+		productPath := createProductFileWithContents([]byte("name: example\nunknown_key: true\n"))
+		command := commands.ValidateTile{
+			Tile: commands.TileArgs{
+				Path: productPath,
+			},
+			Strict: true,
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(MatchError(ContainSubstring("validation error(s)")))
+		Expect(stdout).To(gbytes.Say(`metadata/metadata.yml:2:1: unknown_key: is not a known key`))
+	})
+
+	It("writes unknown keys as json when strict", func() {
+		stdout := gbytes.NewBuffer()
+		productPath := createProductFileWithContents([]byte("name: example\nunknown_key: true\n"))
+		command := commands.ValidateTile{
+			Tile: commands.TileArgs{
+				Path: productPath,
+			},
+			Strict: true,
+			Output: "json",
+			Stdout: stdout,
+		}
+		err := command.Execute(nil)
+		Expect(err).To(HaveOccurred())
+
+		var outputs []map[string]interface{}
+		Expect(json.Unmarshal(stdout.Contents(), &outputs)).To(Succeed())
+		Expect(outputs[0]).To(HaveKeyWithValue("path", "unknown_key"))
+		Expect(outputs[0]).To(HaveKeyWithValue("line", BeNumerically("==", 2)))
 	})
 })
 
+func validPayload() metadata.Payload {
+	return metadata.Payload{
+		Name:                     "example",
+		IconImage:                "image",
+		ProductVersion:           "1.0.0",
+		MinimumVersionForUpgrade: "0.0.0",
+		Releases: []metadata.Release{
+			{Name: "release", File: "release.tgz", Version: "1.0.0"},
+		},
+		StemcellCriteria: metadata.StemcellCriteria{
+			OS:      "ubuntu-xenial",
+			Version: "621.0",
+		},
+	}
+}
+
 func createProductFile(payload metadata.Payload) string {
+	contents, err := yaml.Marshal(payload)
+	Expect(err).NotTo(HaveOccurred())
+
+	return createProductFileWithContents(contents)
+}
+
+func createProductFileWithContents(contents []byte) string {
 	dir, err := ioutil.TempDir("", "")
 	Expect(err).NotTo(HaveOccurred())
 
@@ -44,9 +173,6 @@ func createProductFile(payload metadata.Payload) string {
 	metadataFile, err := os.Create(filepath.Join(metadataPath, "metadata.yml"))
 	Expect(err).NotTo(HaveOccurred())
 
-	contents, err := yaml.Marshal(payload)
-	Expect(err).NotTo(HaveOccurred())
-
 	_, err = metadataFile.Write(contents)
 	Expect(err).NotTo(HaveOccurred())
 	err = metadataFile.Close()
@@ -0,0 +1,123 @@
+package commands
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/jtarchie/tile-builder/metadata"
+)
+
+type validationOutput struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path"`
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+func writeValidations(stdout io.Writer, format string, source metadata.Source, validations []metadata.ValidationError) error {
+	switch format {
+	case "json":
+		return writeValidationsAsJSON(stdout, source, validations)
+	case "junit":
+		return writeValidationsAsJUnit(stdout, source, validations)
+	}
+
+	printValidations(stdout, source, validations)
+
+	return nil
+}
+
+func printValidations(stdout io.Writer, source metadata.Source, validations []metadata.ValidationError) {
+	for _, validation := range validations {
+		_, _ = fmt.Fprintln(stdout, formatValidation(source, validation))
+	}
+}
+
+func formatValidation(source metadata.Source, validation metadata.ValidationError) string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s", source.Filename, validation.Line, validation.Column, validation.Path, validation.Message)
+}
+
+func writeValidationsAsJSON(stdout io.Writer, source metadata.Source, validations []metadata.ValidationError) error {
+	outputs := []validationOutput{}
+	for _, validation := range validations {
+		outputs = append(outputs, validationOutput{
+			File:    source.Filename,
+			Line:    validation.Line,
+			Column:  validation.Column,
+			Path:    validation.Path,
+			Field:   validation.Field,
+			Message: validation.Message,
+		})
+	}
+
+	encoder := json.NewEncoder(stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(outputs)
+}
+
+func writeValidationsAsJUnit(stdout io.Writer, source metadata.Source, validations []metadata.ValidationError) error {
+	suite := junitTestSuite{
+		Name:     "validate-tile",
+		Failures: len(validations),
+	}
+
+	for _, validation := range validations {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      validation.Path,
+			ClassName: source.Filename,
+			Failure: &junitFailure{
+				Message:  validation.Message,
+				Contents: formatValidation(source, validation),
+			},
+		})
+	}
+
+	if len(suite.TestCases) == 0 {
+		suite.TestCases = append(suite.TestCases, junitTestCase{
+			Name:      "metadata",
+			ClassName: source.Filename,
+		})
+	}
+
+	suite.Tests = len(suite.TestCases)
+
+	_, err := io.WriteString(stdout, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(stdout)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(suite)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(stdout, "\n")
+	return err
+}
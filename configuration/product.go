@@ -21,7 +21,7 @@ type NetworkProperties struct {
 	OtherAvailabilityZones []AvailabilityZone `yaml:"other_availability_zones,omitempty" validate:"dive"`
 	ServiceNetwork         struct {
 		Name string `validate:"required"`
-	} `yaml:",omitempty" validate:"dive"`
+	} `yaml:"service_network,omitempty" validate:"dive"`
 	SingletonAvailabilityZone struct {
 		Name string `validate:"required"`
 	} `yaml:"singleton_availability_zone,omitempty" validate:"dive"`
@@ -29,7 +29,7 @@ type NetworkProperties struct {
 
 // https://docs.pivotal.io/pivotalcf/2-5/opsman-api/#retrieving-resources-for-a-job
 type ResourceConfig struct {
-	Instances    int `validate:"gte=0"`
+	Instances    interface{} `yaml:",omitempty"`
 	InstanceType struct {
 		ID string `validate:"required"`
 	} `yaml:"instance_type,omitempty" validate:"dive"`
@@ -38,7 +38,7 @@ type ResourceConfig struct {
 	} `yaml:"persistent_disk,omitempty" validate:"dive"`
 
 	// AWS, Google, and Azure
-	InternetConnected bool     `yaml:"internet_connected"`
+	InternetConnected bool     `yaml:"internet_connected,omitempty"`
 	ElbNames          []string `yaml:"elb_names,omitempty"`
 
 	// Vsphere
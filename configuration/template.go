@@ -0,0 +1,187 @@
+package configuration
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jtarchie/tile-builder/metadata"
+	"gopkg.in/yaml.v2"
+)
+
+// Template creates a product config for the tile, with every configurable
+// property set to its default or a placeholder for its type. Optional
+// properties are written, but commented out.
+func Template(payload metadata.Payload) ([]byte, error) {
+	var product Product
+
+	product.Name = payload.Name
+	product.NetworkProperties.Network.Name = "((network_name))"
+	product.NetworkProperties.SingletonAvailabilityZone.Name = "((singleton_availability_zone))"
+	product.NetworkProperties.OtherAvailabilityZones = []AvailabilityZone{{Name: "((availability_zone))"}}
+	if payload.RequiresServiceNetwork() {
+		product.NetworkProperties.ServiceNetwork.Name = "((service_network_name))"
+	}
+
+	optional := map[string]bool{}
+	product.ProductProperties = map[string]Property{}
+	addProperties(".properties", payload.PropertyBlueprints, false, product.ProductProperties, optional)
+	for _, jobType := range payload.JobTypes {
+		addProperties(fmt.Sprintf(".%s", jobType.Name), jobType.PropertyBlueprints, false, product.ProductProperties, optional)
+	}
+
+	product.ResourceConfig = map[string]ResourceConfig{}
+	for _, jobType := range payload.JobTypes {
+		product.ResourceConfig[jobType.Name] = ResourceConfig{
+			Instances: jobType.InstanceDefinition.Default,
+		}
+	}
+
+	contents := &bytes.Buffer{}
+
+	err := writeYAML(contents, map[string]interface{}{
+		"name":               product.Name,
+		"network-properties": product.NetworkProperties,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = writeProductProperties(contents, product.ProductProperties, optional)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(product.ResourceConfig) > 0 {
+		err = writeYAML(contents, map[string]interface{}{
+			"resource-config": product.ResourceConfig,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return contents.Bytes(), nil
+}
+
+func addProperties(prefix string, blueprints []metadata.PropertyBlueprint, optionalParent bool, properties map[string]Property, optional map[string]bool) {
+	for _, pb := range blueprints {
+		reference := fmt.Sprintf("%s.%s", prefix, pb.Name)
+
+		if pb.Configurable {
+			properties[reference] = Property{Value: placeholderValue(reference, pb)}
+			optional[reference] = optionalParent || pb.Optional
+		}
+
+		for _, optionTemplate := range pb.OptionTemplates {
+			selected := pb.Default == optionTemplate.SelectValue || pb.Default == optionTemplate.Name
+			addProperties(
+				fmt.Sprintf("%s.%s", reference, optionTemplate.Name),
+				optionTemplate.PropertyBlueprints,
+				optionalParent || !selected,
+				properties,
+				optional,
+			)
+		}
+	}
+}
+
+func placeholderValue(reference string, pb metadata.PropertyBlueprint) interface{} {
+	if pb.Default != nil {
+		return pb.Default
+	}
+
+	variable := func(suffix ...string) string {
+		name := strings.Replace(strings.TrimPrefix(reference, "."), ".", "_", -1)
+		return fmt.Sprintf("((%s))", strings.Join(append([]string{name}, suffix...), "."))
+	}
+
+	switch pb.Type {
+	case "boolean":
+		return false
+	case "dropdown_select":
+		if len(pb.Options) > 0 {
+			return pb.Options[0].Name
+		}
+	case "selector":
+		if len(pb.OptionTemplates) > 0 {
+			return pb.OptionTemplates[0].SelectValue
+		}
+	case "multi_select_options", "service_network_az_multi_select":
+		return []interface{}{variable()}
+	case "collection":
+		entry := map[string]interface{}{}
+		for _, nested := range pb.PropertyBlueprints {
+			entry[nested.Name] = placeholderValue(fmt.Sprintf("%s.%s", reference, nested.Name), nested)
+		}
+		return []interface{}{entry}
+	case "rsa_cert_credentials":
+		return map[string]interface{}{
+			"cert_pem":        variable("certificate"),
+			"private_key_pem": variable("private_key"),
+		}
+	case "rsa_pkey_credentials":
+		return map[string]interface{}{
+			"public_key_pem":  variable("public_key"),
+			"private_key_pem": variable("private_key"),
+		}
+	case "simple_credentials", "salted_credentials":
+		return map[string]interface{}{
+			"identity": variable("username"),
+			"password": variable("password"),
+		}
+	case "secret":
+		return map[string]interface{}{
+			"secret": variable(),
+		}
+	}
+
+	return variable()
+}
+
+func writeProductProperties(contents *bytes.Buffer, properties map[string]Property, optional map[string]bool) error {
+	if len(properties) == 0 {
+		return nil
+	}
+
+	references := []string{}
+	for reference := range properties {
+		references = append(references, reference)
+	}
+
+	sort.Strings(references)
+
+	contents.WriteString("product-properties:\n")
+
+	for _, reference := range references {
+		property, err := yaml.Marshal(map[string]Property{
+			reference: properties[reference],
+		})
+		if err != nil {
+			return fmt.Errorf("could not marshal property %s: %s", reference, err)
+		}
+
+		prefix := "  "
+		if optional[reference] {
+			prefix = "  # "
+		}
+
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(string(property), "\n"), "\n") {
+			contents.WriteString(prefix + line)
+		}
+		contents.WriteString("\n")
+	}
+
+	return nil
+}
+
+func writeYAML(contents *bytes.Buffer, value interface{}) error {
+	section, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = contents.Write(section)
+	return err
+}
@@ -0,0 +1,97 @@
+package configuration_test
+
+import (
+	"github.com/jtarchie/tile-builder/configuration"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Template", func() {
+	It("creates a product config from the tile", func() {
+		contents, err := configuration.Template(metadata.Payload{
+			Name: "example",
+			PropertyBlueprints: []metadata.PropertyBlueprint{
+				{Name: "port", Type: "port", Configurable: true, Default: 8080},
+				{Name: "domain", Type: "domain", Configurable: true},
+				{Name: "enabled", Type: "boolean", Configurable: true},
+				{Name: "certificate", Type: "rsa_cert_credentials", Configurable: true},
+				{Name: "comment", Type: "string", Configurable: true, Optional: true},
+				{Name: "internal", Type: "string"},
+				{
+					Name:         "tls",
+					Type:         "selector",
+					Configurable: true,
+					Default:      "disabled",
+					OptionTemplates: []metadata.OptionTemplate{
+						{Name: "disabled", SelectValue: "disabled"},
+						{
+							Name:        "enabled",
+							SelectValue: "enabled",
+							PropertyBlueprints: []metadata.PropertyBlueprint{
+								{Name: "ciphers", Type: "string", Configurable: true},
+							},
+						},
+					},
+				},
+			},
+			JobTypes: []metadata.JobType{
+				{
+					Name: "router",
+					InstanceDefinition: metadata.InstanceDefinition{
+						Default: 3,
+					},
+					PropertyBlueprints: []metadata.PropertyBlueprint{
+						{Name: "timeout", Type: "integer", Configurable: true},
+					},
+				},
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(Equal(`name: example
+network-properties:
+  network:
+    name: ((network_name))
+  other_availability_zones:
+  - name: ((availability_zone))
+  singleton_availability_zone:
+    name: ((singleton_availability_zone))
+product-properties:
+  .properties.certificate:
+    value:
+      cert_pem: ((properties_certificate.certificate))
+      private_key_pem: ((properties_certificate.private_key))
+  # .properties.comment:
+  #   value: ((properties_comment))
+  .properties.domain:
+    value: ((properties_domain))
+  .properties.enabled:
+    value: false
+  .properties.port:
+    value: 8080
+  .properties.tls:
+    value: disabled
+  # .properties.tls.enabled.ciphers:
+  #   value: ((properties_tls_enabled_ciphers))
+  .router.timeout:
+    value: ((router_timeout))
+resource-config:
+  router:
+    instances: 3
+`))
+
+		var product configuration.Product
+		Expect(yaml.UnmarshalStrict(contents, &product)).To(Succeed())
+		Expect(product.ProductProperties).To(HaveLen(6))
+	})
+
+	It("adds the service network when the tile needs one", func() {
+		contents, err := configuration.Template(metadata.Payload{
+			Name:          "example",
+			ServiceBroker: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(contents)).To(ContainSubstring("service_network:\n    name: ((service_network_name))"))
+	})
+})
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// PropertyClash is a property defined by more than one job of a release with
+// a different default, example or type. In one global property namespace only
+// one of the definitions ends up in the tile.
+type PropertyClash struct {
+	Release  string
+	Property string
+	Jobs     []string
+}
+
+func (c PropertyClash) String() string {
+	return fmt.Sprintf("property %s of release %s is defined differently by jobs %s", c.Property, c.Release, strings.Join(c.Jobs, ", "))
+}
+
+func PropertyClashes(releases ...BoshReleasePayload) []PropertyClash {
+	var clashes []PropertyClash
+
+	for _, release := range releases {
+		definitions := map[string][]SpecPayload{}
+		names := []string{}
+
+		for _, spec := range release.Specs {
+			for name := range spec.Properties {
+				if _, found := definitions[name]; !found {
+					names = append(names, name)
+				}
+				definitions[name] = append(definitions[name], spec)
+			}
+		}
+
+		sort.Strings(names)
+
+		for _, name := range names {
+			specs := definitions[name]
+			first := specs[0].Properties[name]
+
+			clashing := false
+			for _, spec := range specs[1:] {
+				if !sameDefinition(first, spec.Properties[name]) {
+					clashing = true
+					break
+				}
+			}
+
+			if !clashing {
+				continue
+			}
+
+			clash := PropertyClash{Release: release.Name, Property: name}
+			for _, spec := range specs {
+				clash.Jobs = append(clash.Jobs, spec.Name)
+			}
+			sort.Strings(clash.Jobs)
+
+			clashes = append(clashes, clash)
+		}
+	}
+
+	return clashes
+}
+
+func sameDefinition(a, b Property) bool {
+	return a.Type == b.Type &&
+		reflect.DeepEqual(a.Default, b.Default) &&
+		reflect.DeepEqual(a.Example, b.Example)
+}
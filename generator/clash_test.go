@@ -0,0 +1,40 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PropertyClashes", func() {
+	spec := func(contents string) generator.SpecPayload {
+		spec, err := generator.ParseSpec(writeFile(contents))
+		Expect(err).NotTo(HaveOccurred())
+
+		return spec
+	}
+
+	It("reports properties defined differently by jobs of a release", func() {
+		clashes := generator.PropertyClashes(generator.BoshReleasePayload{
+			Name: "my-release",
+			Specs: []generator.SpecPayload{
+				spec("{name: web, properties: {port: {default: 8080}, log_level: {default: info}}}"),
+				spec("{name: worker, properties: {port: {default: 9090}, log_level: {default: info}}}"),
+				spec("{name: api, properties: {port: {default: 8080, type: integer}}}"),
+			},
+		})
+
+		Expect(clashes).To(Equal([]generator.PropertyClash{
+			{Release: "my-release", Property: "port", Jobs: []string{"api", "web", "worker"}},
+		}))
+		Expect(clashes[0].String()).To(Equal("property port of release my-release is defined differently by jobs api, web, worker"))
+	})
+
+	It("ignores properties of jobs in different releases", func() {
+		clashes := generator.PropertyClashes(
+			generator.BoshReleasePayload{Name: "my-release", Specs: []generator.SpecPayload{spec("{name: web, properties: {port: {default: 8080}}}")}},
+			generator.BoshReleasePayload{Name: "other-release", Specs: []generator.SpecPayload{spec("{name: worker, properties: {port: {default: 9090}}}")}},
+		)
+		Expect(clashes).To(BeEmpty())
+	})
+})
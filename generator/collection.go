@@ -0,0 +1,158 @@
+package generator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jtarchie/tile-builder/metadata"
+)
+
+// listOfMaps returns the entries of a value that is a non-empty list of
+// maps, the shape of a collection.
+func listOfMaps(value interface{}) ([]map[interface{}]interface{}, bool) {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+
+	var entries []map[interface{}]interface{}
+	for _, item := range list {
+		entry, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, true
+}
+
+// collectionBlueprints infers the property blueprints of a collection's
+// entries from a list of maps in the default or example. A map, or a
+// collection without a list to learn from, has generic key and value entries.
+func collectionBlueprints(name string, property Property) ([]metadata.PropertyBlueprint, error) {
+	entries, ok := listOfMaps(property.Default)
+	if !ok {
+		entries, ok = listOfMaps(property.Example)
+	}
+
+	if !ok {
+		return []metadata.PropertyBlueprint{
+			{
+				Name:         "key",
+				Type:         "string",
+				Optional:     true,
+				Configurable: true,
+			},
+			{
+				Name:         "value",
+				Type:         "string",
+				Optional:     true,
+				Configurable: true,
+			},
+		}, nil
+	}
+
+	examples := map[string]interface{}{}
+	occurrences := map[string]int{}
+
+	for _, entry := range entries {
+		for key, value := range entry {
+			field := fmt.Sprintf("%v", key)
+
+			occurrences[field]++
+			if examples[field] == nil {
+				examples[field] = value
+			}
+		}
+	}
+
+	fields := []string{}
+	for field := range occurrences {
+		fields = append(fields, field)
+	}
+
+	sort.Strings(fields)
+
+	var blueprints []metadata.PropertyBlueprint
+
+	for _, field := range fields {
+		// only the value is used, as field names like `key` or `password`
+		// are too generic to infer a type from
+		pbType, ok := valueType(examples[field])
+		if !ok {
+			return nil, fmt.Errorf("not able to determine type for field %s of property %s: %#v", field, name, examples[field])
+		}
+
+		// a collection's entries cannot be collections themselves
+		if pbType == "collection" {
+			pbType = "text"
+		}
+
+		blueprints = append(blueprints, metadata.PropertyBlueprint{
+			Name:         field,
+			Type:         pbType,
+			Configurable: true,
+			Optional:     occurrences[field] < len(entries) || examples[field] == nil,
+		})
+	}
+
+	return blueprints, nil
+}
+
+// collectionEntries converts the entries of a default to the types of the
+// collection's blueprints, e.g. a nested map of a text field becomes YAML.
+func collectionEntries(name string, entries []map[interface{}]interface{}, blueprints []metadata.PropertyBlueprint) ([]map[string]interface{}, error) {
+	types := map[string]string{}
+	for _, pb := range blueprints {
+		types[pb.Name] = pb.Type
+	}
+
+	var converted []map[string]interface{}
+
+	for _, entry := range entries {
+		item := map[string]interface{}{}
+		for key, value := range entry {
+			field := fmt.Sprintf("%v", key)
+
+			value, err := entryValue(types[field], value)
+			if err != nil {
+				return nil, fmt.Errorf("could not determine default for field %s of %s: %s", field, name, err)
+			}
+
+			item[field] = value
+		}
+
+		converted = append(converted, item)
+	}
+
+	return converted, nil
+}
+
+func entryValue(pbType string, value interface{}) (interface{}, error) {
+	switch pbType {
+	case "string", "text":
+		return formatDefault(value)
+	case "string_list":
+		list, ok := value.([]interface{})
+		if !ok {
+			return formatDefault(value)
+		}
+
+		items := []string{}
+		for _, item := range list {
+			formatted, err := formatDefault(item)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, formatted)
+		}
+
+		return strings.Join(items, ","), nil
+	}
+
+	return value, nil
+}
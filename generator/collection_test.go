@@ -0,0 +1,120 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generating collections", func() {
+	generate := func(spec string) metadata.Payload {
+		payload, err := generator.ParseSpec(writeFile(spec))
+		Expect(err).NotTo(HaveOccurred())
+
+		tile, err := generator.Tile(generator.Options{}, generator.BoshReleasePayload{
+			Name:  "my-release",
+			Specs: []generator.SpecPayload{payload},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return tile
+	}
+
+	It("infers the entries from a list of maps default", func() {
+		tile := generate(`
+name: router
+properties:
+  router.routes:
+    default:
+    - name: api
+      port: 8080
+      tls: true
+    - name: web
+      port: 80
+      password: secret
+`)
+
+		pb := tile.PropertyBlueprints[0]
+		Expect(pb.Name).To(Equal("router__routes"))
+		Expect(pb.Type).To(Equal("collection"))
+		Expect(pb.Optional).To(BeFalse())
+		Expect(pb.PropertyBlueprints).To(Equal([]metadata.PropertyBlueprint{
+			{Name: "name", Type: "string", Configurable: true},
+			{Name: "password", Type: "string", Configurable: true, Optional: true},
+			{Name: "port", Type: "integer", Configurable: true},
+			{Name: "tls", Type: "boolean", Configurable: true, Optional: true},
+		}))
+		Expect(pb.Default).To(Equal([]map[string]interface{}{
+			{"name": "api", "port": 8080, "tls": true},
+			{"name": "web", "port": 80, "password": "secret"},
+		}))
+		Expect(pb.ValidateValue(pb.Default)).To(Succeed())
+
+		Expect(tile.JobTypes[0].Manifest).To(MatchYAML(`
+router:
+  routes: ((.properties.router__routes.value))
+`))
+	})
+
+	It("converts the nested values of the entries to their fields' types", func() {
+		tile := generate(`
+name: router
+properties:
+  router.routes:
+    default:
+    - name: api
+      options: {timeout: 30}
+      backends: [{host: a}]
+      ports: [80, 443]
+`)
+
+		pb := tile.PropertyBlueprints[0]
+		Expect(pb.PropertyBlueprints).To(Equal([]metadata.PropertyBlueprint{
+			{Name: "backends", Type: "text", Configurable: true},
+			{Name: "name", Type: "string", Configurable: true},
+			{Name: "options", Type: "text", Configurable: true},
+			{Name: "ports", Type: "string_list", Configurable: true},
+		}))
+		Expect(pb.Default).To(Equal([]map[string]interface{}{
+			{"name": "api", "options": "timeout: 30", "backends": "- host: a", "ports": "80,443"},
+		}))
+		Expect(pb.ValidateValue(pb.Default)).To(Succeed())
+	})
+
+	It("does not infer the type of an entry from its name", func() {
+		tile := generate(`
+name: proxy
+properties:
+  proxy.headers:
+    default:
+    - key: X-Foo
+      value: bar
+`)
+
+		Expect(tile.PropertyBlueprints[0].PropertyBlueprints).To(Equal([]metadata.PropertyBlueprint{
+			{Name: "key", Type: "string", Configurable: true},
+			{Name: "value", Type: "string", Configurable: true},
+		}))
+	})
+
+	It("infers the entries from a list of maps example without a default", func() {
+		tile := generate(`
+name: router
+properties:
+  router.routes:
+    example:
+    - name: api
+      hosts: [api.example.com]
+`)
+
+		pb := tile.PropertyBlueprints[0]
+		Expect(pb.Type).To(Equal("collection"))
+		Expect(pb.Optional).To(BeTrue())
+		Expect(pb.Default).To(BeNil())
+		Expect(pb.PropertyBlueprints).To(Equal([]metadata.PropertyBlueprint{
+			{Name: "hosts", Type: "string_list", Configurable: true},
+			{Name: "name", Type: "string", Configurable: true},
+		}))
+	})
+})
@@ -0,0 +1,32 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Creating the manifest from a property", func() {
+	DescribeTable("renders the accessors of the property blueprint", func(name string, property generator.Property, expected interface{}) {
+		manifest, err := generator.CreateManifestFromProperty(name, property)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(manifest).To(Equal(expected))
+	},
+		Entry("for a string", "some.name", generator.Property{}, "((.properties.some__name.value))"),
+		Entry("for a certificate", "some.tls", generator.Property{Type: "certificate"}, map[string]string{
+			"certificate": "((.properties.some__tls.certificate))",
+			"private_key": "((.properties.some__tls.private_key))",
+		}),
+		Entry("for a rsa key", "some.ssh", generator.Property{Type: "ssh"}, map[string]string{
+			"public_key":  "((.properties.some__ssh.public_key))",
+			"private_key": "((.properties.some__ssh.private_key))",
+		}),
+		Entry("for credentials", "admin_credentials", generator.Property{}, map[string]string{
+			"identity": "((.properties.admin_credentials.identity))",
+			"password": "((.properties.admin_credentials.password))",
+		}),
+		Entry("for a password", "admin.password", generator.Property{}, "((.properties.admin__password.secret))"),
+		Entry("for a bosh password", "admin.pass", generator.Property{Type: "password"}, "((.properties.admin__pass.secret))"),
+	)
+})
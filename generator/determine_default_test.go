@@ -19,6 +19,24 @@ var _ = Describe("Determining the property default value", func() {
 		Entry("default is a integer", generator.Property{Default: 1}, Equal(1)),
 		Entry("default is a string", generator.Property{Default: "asdf"}, Equal("asdf")),
 		Entry("default is a array of strings", generator.Property{Default: []interface{}{"a", "b"}}, Equal("a,b")),
-		Entry("default is a map", generator.Property{Default: map[interface{}]interface{}{}}, BeNil()),
+		Entry("default is a array of integers", generator.Property{Default: []interface{}{1, 2.5, true}}, Equal("1,2.5,true")),
+		Entry("default is a empty map", generator.Property{Default: map[interface{}]interface{}{}}, BeEmpty()),
+		Entry("default is a map", generator.Property{Default: map[interface{}]interface{}{
+			"b": 2,
+			"a": "one",
+			"c": map[interface{}]interface{}{"nested": true},
+		}}, Equal([]map[string]interface{}{
+			{"key": "a", "value": "one"},
+			{"key": "b", "value": "2"},
+			{"key": "c", "value": "nested: true"},
+		})),
+		Entry("default is a list of maps", generator.Property{Default: []interface{}{
+			map[interface{}]interface{}{"name": "value"},
+		}}, Equal([]map[string]interface{}{{"name": "value"}})),
 	)
+
+	It("errors on an unknown type of default", func() {
+		_, err := generator.DeterminePropertyBlueprintDefault("some.property", generator.Property{Default: struct{}{}})
+		Expect(err).To(MatchError("could not determine default for some.property of struct {}"))
+	})
 })
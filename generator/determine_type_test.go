@@ -31,6 +31,21 @@ var _ = Describe("Determining the property type", func() {
 		Entry("when type is a certificate", "", generator.Property{Type: "certificate"}, "rsa_cert_credentials"),
 		Entry("when type is a rsa", "", generator.Property{Type: "rsa"}, "rsa_pkey_credentials"),
 		Entry("when type is a ssh", "", generator.Property{Type: "ssh"}, "rsa_pkey_credentials"),
+		Entry("when type is a password", "", generator.Property{Type: "password"}, "secret"),
+
+		Entry("when the name is a password", "admin.password", generator.Property{}, "secret"),
+		Entry("when the name ends in _secret", "client_secret", generator.Property{}, "secret"),
+		Entry("when the name ends in _token", "api_token", generator.Property{}, "secret"),
+		Entry("when the name ends in _key", "encryption_key", generator.Property{}, "secret"),
+		Entry("when the name includes key", "monkey", generator.Property{}, "string"),
+		Entry("when the name is a password with a default", "admin.password", generator.Property{Default: "admin"}, "string"),
+		Entry("when the name ends in credentials", "admin_credentials", generator.Property{}, "simple_credentials"),
+		Entry("when the description mentions a password", "admin.pass", generator.Property{Description: "The Password of the admin"}, "secret"),
+		Entry("when the description mentions a token", "admin.auth", generator.Property{Description: "The API token of the admin"}, "secret"),
+		Entry("when the description mentions a key", "admin.signing", generator.Property{Description: "Key to sign the sessions with"}, "secret"),
+		Entry("when the default is a large integer", "", generator.Property{Default: uint64(18446744073709551615)}, "integer"),
+		Entry("when the default is a negative large integer", "", generator.Property{Default: int64(-9223372036854775808)}, "integer"),
+		Entry("when the description mentions a password with an example", "admin.pass", generator.Property{Description: "The Password of the admin", Example: "changeme"}, "string"),
 
 		Entry("when the name includes _port", "server_port", generator.Property{}, "port"),
 		Entry("when the name includes .port", "server.port", generator.Property{}, "port"),
@@ -53,5 +68,8 @@ var _ = Describe("Determining the property type", func() {
 		Entry("when the example is a map", "", generator.Property{Example: map[interface{}]interface{}{
 			"name": "value",
 		}}, "collection"),
+		Entry("when the default is a list of maps", "", generator.Property{Default: []interface{}{
+			map[interface{}]interface{}{"name": "value"},
+		}}, "collection"),
 	)
 })
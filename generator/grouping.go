@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	GroupByPrefix = "prefix"
+	GroupByJob    = "job"
+)
+
+// Grouping chooses the form of each property. Properties matching a group
+// are put in its form, the others are grouped by the strategy of By: the
+// first Depth segments of their name, or the job defining them. It defaults
+// to grouping by job when the property blueprints are job scoped, and by
+// prefix otherwise.
+type Grouping struct {
+	By     string
+	Depth  int
+	Groups []Group
+}
+
+// Group is a form, from a grouping file, for the properties it matches. Its
+// properties are regular expressions matching anywhere in a property name, so
+// `web` also matches `webhook.url`; anchor them, like `\Aweb\.`, to match the
+// properties under `web` only.
+type Group struct {
+	Name       string
+	Label      string `yaml:",omitempty"`
+	Properties []string
+
+	patterns []*regexp.Regexp
+}
+
+func LoadGroups(path string) ([]Group, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read groups %s: %s", path, err)
+	}
+
+	var groups []Group
+
+	err = yaml.UnmarshalStrict(contents, &groups)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal groups %s: %s", path, err)
+	}
+
+	for index, group := range groups {
+		if group.Name == "" {
+			return nil, fmt.Errorf("group %d in %s has no name", index, path)
+		}
+
+		if len(group.Properties) == 0 {
+			return nil, fmt.Errorf("group %s in %s has no properties", group.Name, path)
+		}
+
+		for _, property := range group.Properties {
+			pattern, err := regexp.Compile(property)
+			if err != nil {
+				return nil, fmt.Errorf("group %s in %s has an invalid property pattern: %s", group.Name, path, err)
+			}
+
+			groups[index].patterns = append(groups[index].patterns, pattern)
+		}
+	}
+
+	return groups, nil
+}
+
+func (g Group) matches(name string) bool {
+	patterns := g.patterns
+	if patterns == nil {
+		for _, property := range g.Properties {
+			pattern, err := regexp.Compile(property)
+			if err != nil {
+				return false
+			}
+
+			patterns = append(patterns, pattern)
+		}
+	}
+
+	for _, pattern := range patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formGroups assigns the properties of a release, or of a job when the
+// property blueprints are job scoped, to forms.
+type formGroups struct {
+	grouping Grouping
+	// prefix keeps the form names unique across releases or jobs
+	prefix string
+	// jobs is the first job defining each property
+	jobs map[string]string
+}
+
+func newFormGroups(grouping Grouping, prefix string, specs []SpecPayload, jobScoped bool) formGroups {
+	if grouping.By == "" {
+		grouping.By = GroupByPrefix
+		if jobScoped {
+			grouping.By = GroupByJob
+		}
+	}
+
+	jobs := map[string]string{}
+	for _, spec := range specs {
+		for name := range spec.Properties {
+			if _, found := jobs[name]; !found {
+				jobs[name] = spec.Name
+			}
+		}
+	}
+
+	return formGroups{
+		grouping: grouping,
+		prefix:   prefix,
+		jobs:     jobs,
+	}
+}
+
+// name is the form of the property, or of the selector, with the name.
+func (f formGroups) name(property string) string {
+	for _, group := range f.grouping.Groups {
+		if group.matches(property) {
+			return formName(f.prefix, group.Name)
+		}
+	}
+
+	if f.grouping.By == GroupByJob {
+		// job names are unique within a tile
+		return formName("", f.job(property))
+	}
+
+	depth := f.grouping.Depth
+	if depth < 1 {
+		depth = 1
+	}
+
+	parts := strings.Split(property, ".")
+	if depth > len(parts)-1 {
+		depth = len(parts) - 1
+	}
+
+	if depth == 0 {
+		return f.fallback()
+	}
+
+	return formName(f.prefix, strings.Join(parts[:depth], "_"))
+}
+
+func (f formGroups) job(property string) string {
+	if job, found := f.jobs[property]; found {
+		return job
+	}
+
+	names := []string{}
+	for name := range f.jobs {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	// a selector is named after the prefix of its properties
+	for _, name := range names {
+		if strings.HasPrefix(name, property+".") {
+			return f.jobs[name]
+		}
+	}
+
+	return ""
+}
+
+// fallback is the form of the properties without a group of their own.
+func (f formGroups) fallback() string {
+	return formName(f.prefix, "properties")
+}
+
+func (f formGroups) label(form string) string {
+	for _, group := range f.grouping.Groups {
+		if group.Label != "" && formName(f.prefix, group.Name) == form {
+			return group.Label
+		}
+	}
+
+	return strings.Title(breakApartName(form))
+}
+
+// merge moves the properties of forms with a single input to the fallback
+// form. Only the forms of prefixes are merged, the forms of groups and jobs
+// are kept as they are. A lone single input form is kept, as there is nothing
+// to merge it with.
+func (f formGroups) merge(namesByForm map[string][]string) {
+	if f.grouping.By != GroupByPrefix {
+		return
+	}
+
+	var single []string
+
+	for form, names := range namesByForm {
+		if form != f.fallback() && !f.declared(form) && len(names) == 1 {
+			single = append(single, form)
+		}
+	}
+
+	if len(single) == 0 || (len(single) == 1 && len(namesByForm[f.fallback()]) == 0) {
+		return
+	}
+
+	for _, form := range single {
+		namesByForm[f.fallback()] = append(namesByForm[f.fallback()], namesByForm[form]...)
+		delete(namesByForm, form)
+	}
+}
+
+// declared is true for the form of a group from the grouping file.
+func (f formGroups) declared(form string) bool {
+	for _, group := range f.grouping.Groups {
+		if formName(f.prefix, group.Name) == form {
+			return true
+		}
+	}
+
+	return false
+}
+
+// formName makes the name a valid identifier, e.g. `web-server` becomes
+// `web_server`.
+func formName(prefix, name string) string {
+	if prefix != "" {
+		name = fmt.Sprintf("%s_%s", prefix, name)
+	}
+
+	name = strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return "properties"
+	}
+
+	if name[0] >= '0' && name[0] <= '9' {
+		return fmt.Sprintf("form_%s", name)
+	}
+
+	return name
+}
@@ -0,0 +1,160 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Grouping properties into forms", func() {
+	const webSpec = `
+name: web-server
+properties:
+  web.listen.port:
+    default: 8080
+  web.listen.address:
+    default: 0.0.0.0
+  web.log.level:
+    default: info
+  web.log.format:
+    default: json
+  web.name:
+    description: A name without a default
+  web.ssl.ciphers:
+    default: ECDHE
+`
+	const workerSpec = `
+name: 9-worker
+properties:
+  worker.threads:
+    default: 4
+  worker.queue:
+    default: jobs
+`
+
+	var releases []generator.BoshReleasePayload
+
+	BeforeEach(func() {
+		web, err := generator.ParseSpec(writeFile(webSpec))
+		Expect(err).NotTo(HaveOccurred())
+
+		worker, err := generator.ParseSpec(writeFile(workerSpec))
+		Expect(err).NotTo(HaveOccurred())
+
+		releases = []generator.BoshReleasePayload{
+			{Name: "my-release", Specs: []generator.SpecPayload{web, worker}},
+		}
+	})
+
+	forms := func(formTypes []metadata.FormType) map[string][]string {
+		references := map[string][]string{}
+		for _, ft := range formTypes {
+			references[ft.Name] = []string{}
+			for _, input := range ft.PropertyInputs {
+				references[ft.Name] = append(references[ft.Name], input.Reference)
+			}
+		}
+
+		return references
+	}
+
+	It("groups by the first segment of the name by default", func() {
+		tile, err := generator.Tile(generator.Options{}, releases...)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(forms(tile.FormTypes)).To(Equal(map[string][]string{
+			"web": {
+				".properties.web__listen__address",
+				".properties.web__listen__port",
+				".properties.web__log__format",
+				".properties.web__log__level",
+				".properties.web__ssl__ciphers",
+				".properties.web__name",
+			},
+			"worker": {
+				".properties.worker__queue",
+				".properties.worker__threads",
+			},
+		}))
+	})
+
+	It("groups by more segments of the name and merges single input forms", func() {
+		tile, err := generator.Tile(generator.Options{Grouping: generator.Grouping{Depth: 2}}, releases...)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(forms(tile.FormTypes)).To(Equal(map[string][]string{
+			"web_listen": {".properties.web__listen__address", ".properties.web__listen__port"},
+			"web_log":    {".properties.web__log__format", ".properties.web__log__level"},
+			"worker":     {".properties.worker__queue", ".properties.worker__threads"},
+			"properties": {".properties.web__ssl__ciphers", ".properties.web__name"},
+		}))
+	})
+
+	It("groups by job with form names that are identifiers", func() {
+		tile, err := generator.Tile(generator.Options{Grouping: generator.Grouping{By: generator.GroupByJob}}, releases...)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tile.FormTypes[0].Name).To(Equal("form_9_worker"))
+		Expect(tile.FormTypes[0].PropertyInputs).To(HaveLen(2))
+		Expect(tile.FormTypes[1].Name).To(Equal("web_server"))
+		Expect(tile.FormTypes[1].Label).To(Equal("Web Server"))
+		Expect(tile.FormTypes[1].PropertyInputs).To(HaveLen(6))
+	})
+
+	It("groups the properties matching a group from a file", func() {
+		groups, err := generator.LoadGroups(writeFile(`
+- name: networking
+  label: Network Settings
+  properties: ['\.listen\.', '\.ssl\.']
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		tile, err := generator.Tile(generator.Options{Grouping: generator.Grouping{Groups: groups}}, releases...)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tile.FormTypes[0].Name).To(Equal("networking"))
+		Expect(tile.FormTypes[0].Label).To(Equal("Network Settings"))
+		Expect(forms(tile.FormTypes)["networking"]).To(Equal([]string{
+			".properties.web__listen__address",
+			".properties.web__listen__port",
+			".properties.web__ssl__ciphers",
+		}))
+		Expect(forms(tile.FormTypes)["web"]).To(HaveLen(3))
+	})
+
+	It("keeps a group from a file with a single property", func() {
+		groups, err := generator.LoadGroups(writeFile(`[{name: naming, properties: ['\Aweb\.name\z']}]`))
+		Expect(err).NotTo(HaveOccurred())
+
+		tile, err := generator.Tile(generator.Options{Grouping: generator.Grouping{Depth: 2, Groups: groups}}, releases...)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(forms(tile.FormTypes)["naming"]).To(Equal([]string{".properties.web__name"}))
+		Expect(forms(tile.FormTypes)["web_ssl"]).To(Equal([]string{".properties.web__ssl__ciphers"}))
+	})
+
+	It("keeps the form of a job with a single property", func() {
+		single, err := generator.ParseSpec(writeFile(`{name: single, properties: {single.enabled: {default: true}}}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		releases[0].Specs = append(releases[0].Specs, single)
+
+		tile, err := generator.Tile(generator.Options{Grouping: generator.Grouping{By: generator.GroupByJob}}, releases...)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(forms(tile.FormTypes)["single"]).To(Equal([]string{".properties.single__enabled"}))
+	})
+
+	It("errors on invalid groups", func() {
+		_, err := generator.LoadGroups(writeFile(`[{properties: [web]}]`))
+		Expect(err).To(MatchError(ContainSubstring("group 0 in")))
+
+		_, err = generator.LoadGroups(writeFile(`[{name: web}]`))
+		Expect(err).To(MatchError(ContainSubstring("group web in")))
+		Expect(err).To(MatchError(ContainSubstring("has no properties")))
+
+		_, err = generator.LoadGroups(writeFile(`[{name: web, properties: ["("]}]`))
+		Expect(err).To(MatchError(ContainSubstring("invalid property pattern")))
+	})
+})
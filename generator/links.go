@@ -0,0 +1,160 @@
+package generator
+
+import "fmt"
+
+type linkProvider struct {
+	release BoshReleasePayload
+	spec    SpecPayload
+	provide providerPayload
+}
+
+type LinkStatus string
+
+const (
+	LinkWired              LinkStatus = "wired"
+	LinkSelfConsumed       LinkStatus = "self-consumed"
+	LinkCrossDeployment    LinkStatus = "cross-deployment"
+	LinkOptionalUnresolved LinkStatus = "optional-unresolved"
+	LinkRequiredUnresolved LinkStatus = "required-unresolved"
+)
+
+type LinkResolution struct {
+	Job      string
+	Link     string
+	Type     string
+	Status   LinkStatus
+	Provider string
+}
+
+func (l LinkResolution) String() string {
+	switch l.Status {
+	case LinkWired, LinkSelfConsumed:
+		return fmt.Sprintf("%s consumes %s (%s) from %s: %s", l.Job, l.Link, l.Type, l.Provider, l.Status)
+	case LinkCrossDeployment:
+		return fmt.Sprintf("%s consumes %s (%s) from the deployment of %s: %s", l.Job, l.Link, l.Type, l.Provider, l.Status)
+	}
+
+	return fmt.Sprintf("%s consumes %s (%s): %s", l.Job, l.Link, l.Type, l.Status)
+}
+
+// LinkResolutions reports how every link consumed by the jobs of the releases
+// is wired in the generated tile. Links with a status of
+// LinkRequiredUnresolved will fail the deployment.
+func LinkResolutions(options Options, releases ...BoshReleasePayload) []LinkResolution {
+	var resolutions []LinkResolution
+
+	for _, release := range releases {
+		for _, spec := range release.Specs {
+			for _, consume := range spec.Consumes {
+				resolution := LinkResolution{
+					Job:  spec.Name,
+					Link: consume.Name,
+					Type: consume.Type,
+				}
+
+				if provider, found := findProvider(releases, spec, consume); found {
+					resolution.Status = LinkWired
+					if provider.spec.Name == spec.Name {
+						resolution.Status = LinkSelfConsumed
+					}
+					resolution.Provider = provider.spec.Name
+				} else if product, found := options.CrossDeploymentLinks[consume.Name]; found {
+					resolution.Status = LinkCrossDeployment
+					resolution.Provider = product
+				} else if consume.Optional {
+					resolution.Status = LinkOptionalUnresolved
+				} else {
+					resolution.Status = LinkRequiredUnresolved
+				}
+
+				resolutions = append(resolutions, resolution)
+			}
+		}
+	}
+
+	return resolutions
+}
+
+// findProvider finds the job, in any of the releases, that provides the link
+// a job consumes. Another job is preferred over the consuming job providing
+// the link to itself.
+func findProvider(releases []BoshReleasePayload, consumerSpec SpecPayload, consume consumePayload) (linkProvider, bool) {
+	var (
+		self      linkProvider
+		foundSelf bool
+	)
+
+	for _, release := range releases {
+		for _, spec := range release.Specs {
+			for _, provide := range spec.Provides {
+				if provide.Name != consume.Name || provide.Type != consume.Type {
+					continue
+				}
+
+				provider := linkProvider{
+					release: release,
+					spec:    spec,
+					provide: provide,
+				}
+
+				if spec.Name != consumerSpec.Name {
+					return provider, true
+				}
+
+				if !foundSelf {
+					self, foundSelf = provider, true
+				}
+			}
+		}
+	}
+
+	return self, foundSelf
+}
+
+// sharedProperties maps, per job, the properties it shares with the provider
+// of a link it consumes to the reference of the provider's property
+// blueprint. Both jobs can then be configured by the same setting.
+func sharedProperties(releases []BoshReleasePayload, options Options) map[string]map[string]string {
+	shared := map[string]map[string]string{}
+
+	for _, release := range releases {
+		for _, spec := range release.Specs {
+			for _, consume := range spec.Consumes {
+				provider, found := findProvider(releases, spec, consume)
+				if !found {
+					continue
+				}
+
+				for _, name := range provider.provide.Properties {
+					if _, ok := spec.Properties[name]; !ok {
+						continue
+					}
+
+					if _, ok := provider.spec.Properties[name]; !ok {
+						continue
+					}
+
+					if shared[spec.Name] == nil {
+						shared[spec.Name] = map[string]string{}
+					}
+
+					shared[spec.Name][name] = propertyScope(provider.release, releases, provider.spec.Name, options).reference(name)
+				}
+			}
+		}
+	}
+
+	return shared
+}
+
+func crossDeploymentConsumer(consume consumePayload, options Options) (consumer, bool) {
+	product, found := options.CrossDeploymentLinks[consume.Name]
+	if !found {
+		return consumer{}, false
+	}
+
+	return consumer{
+		From:       consume.Name,
+		Deployment: fmt.Sprintf("(( ..%s.deployment_name ))", product),
+	}, true
+}
@@ -0,0 +1,144 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generating links between jobs", func() {
+	const providerSpec = `
+name: server
+provides:
+- name: database
+  type: database
+  properties:
+  - database.port
+properties:
+  database.port:
+    default: 5432
+`
+	const consumerSpec = `
+name: client
+consumes:
+- name: database
+  type: database
+- name: cache
+  type: cache
+properties:
+  database.port:
+    default: 5432
+  client.timeout:
+    default: 30
+`
+
+	var server, client generator.SpecPayload
+
+	BeforeEach(func() {
+		var err error
+
+		server, err = generator.ParseSpec(writeFile(providerSpec))
+		Expect(err).NotTo(HaveOccurred())
+
+		client, err = generator.ParseSpec(writeFile(consumerSpec))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("wires a property exposed over a link to the provider's property blueprint", func() {
+		tile, err := generator.Tile(
+			generator.Options{},
+			generator.BoshReleasePayload{Name: "database-release", Specs: []generator.SpecPayload{server}},
+			generator.BoshReleasePayload{Name: "app-release", Specs: []generator.SpecPayload{client}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		names := []string{}
+		for _, pb := range tile.PropertyBlueprints {
+			names = append(names, pb.Name)
+		}
+		Expect(names).To(Equal([]string{
+			"database_release__database__port",
+			"app_release__client__timeout",
+		}))
+
+		Expect(tile.JobTypes[1].Manifest).To(MatchYAML(`
+client:
+  timeout: ((.properties.app_release__client__timeout.value))
+database:
+  port: ((.properties.database_release__database__port.value))
+`))
+	})
+
+	It("wires a shared property to the provider job's property blueprint when job scoped", func() {
+		tile, err := generator.Tile(
+			generator.Options{JobScoped: true},
+			generator.BoshReleasePayload{Name: "my-release", Specs: []generator.SpecPayload{server, client}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tile.JobTypes[0].PropertyBlueprints[0].Name).To(Equal("database__port"))
+		Expect(tile.JobTypes[1].PropertyBlueprints).To(HaveLen(1))
+		Expect(tile.JobTypes[1].PropertyBlueprints[0].Name).To(Equal("client__timeout"))
+		Expect(tile.JobTypes[1].Manifest).To(MatchYAML(`
+client:
+  timeout: ((.client.client__timeout.value))
+database:
+  port: ((.server.database__port.value))
+`))
+	})
+
+	It("consumes links from another deployment", func() {
+		tile, err := generator.Tile(
+			generator.Options{
+				CrossDeploymentLinks: map[string]string{"cache": "p-redis"},
+			},
+			generator.BoshReleasePayload{Name: "my-release", Specs: []generator.SpecPayload{server, client}},
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tile.JobTypes[1].Templates[0].Consumes).To(MatchYAML(`
+database:
+  from: server-database
+cache:
+  from: cache
+  deployment: (( ..p-redis.deployment_name ))
+`))
+	})
+
+	It("reports how each consumed link is resolved", func() {
+		const selfSpec = `
+name: cluster
+provides:
+- name: peers
+  type: peers
+consumes:
+- name: peers
+  type: peers
+- name: metrics
+  type: metrics
+  optional: true
+`
+		cluster, err := generator.ParseSpec(writeFile(selfSpec))
+		Expect(err).NotTo(HaveOccurred())
+
+		resolutions := generator.LinkResolutions(
+			generator.Options{
+				CrossDeploymentLinks: map[string]string{"cache": "p-redis"},
+			},
+			generator.BoshReleasePayload{Name: "my-release", Specs: []generator.SpecPayload{server, client, cluster}},
+		)
+		Expect(resolutions).To(Equal([]generator.LinkResolution{
+			{Job: "client", Link: "database", Type: "database", Status: generator.LinkWired, Provider: "server"},
+			{Job: "client", Link: "cache", Type: "cache", Status: generator.LinkCrossDeployment, Provider: "p-redis"},
+			{Job: "cluster", Link: "peers", Type: "peers", Status: generator.LinkSelfConsumed, Provider: "cluster"},
+			{Job: "cluster", Link: "metrics", Type: "metrics", Status: generator.LinkOptionalUnresolved},
+		}))
+
+		resolutions = generator.LinkResolutions(
+			generator.Options{},
+			generator.BoshReleasePayload{Name: "my-release", Specs: []generator.SpecPayload{client}},
+		)
+		Expect(resolutions[0].Status).To(Equal(generator.LinkRequiredUnresolved))
+		Expect(resolutions[0].String()).To(Equal("client consumes database (database): required-unresolved"))
+	})
+})
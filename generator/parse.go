@@ -1,11 +1,14 @@
 package generator
 
 import (
+	"crypto/sha1"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/bmatcuk/doublestar"
 	"github.com/mholt/archiver"
@@ -23,6 +26,8 @@ type Property struct {
 	EnvFields   map[string]struct {
 		EnvFile string `yaml:"env_file"`
 	} `yaml:"env_fields"`
+
+	rule *Rule
 }
 
 type consumePayload struct {
@@ -47,10 +52,21 @@ type SpecPayload struct {
 	Properties  map[string]Property `yaml:"properties"`
 }
 
+type CompiledPackage struct {
+	Name            string
+	Version         string
+	StemcellOS      string
+	StemcellVersion string
+	Dependencies    []string
+}
+
 type BoshReleasePayload struct {
-	Specs         []SpecPayload
-	Name          string
-	LatestVersion string
+	Specs            []SpecPayload
+	Name             string
+	LatestVersion    string
+	File             string
+	SHA1             string
+	CompiledPackages []CompiledPackage
 }
 
 func ParseSpec(filename string) (SpecPayload, error) {
@@ -95,6 +111,14 @@ type ReleasePayload struct {
 		Sha1         string `yaml:"sha1"`
 		Dependencies interface{}
 	}
+	CompiledPackages []struct {
+		Name         string
+		Version      string
+		Fingerprint  string
+		Sha1         string `yaml:"sha1"`
+		Stemcell     string
+		Dependencies []string
+	} `yaml:"compiled_packages"`
 	License struct {
 		Version     string
 		Fingerprint string
@@ -157,6 +181,28 @@ func parseReleaseTarball(releasePath string) (BoshReleasePayload, error) {
 
 	boshRelease.Name = release.Name
 	boshRelease.LatestVersion = release.Version
+	// named like the releases of other tiles, as the tarball can be named anything
+	boshRelease.File = fmt.Sprintf("%s-%s.tgz", release.Name, release.Version)
+
+	boshRelease.SHA1, err = fileSHA1(releasePath)
+	if err != nil {
+		return BoshReleasePayload{}, fmt.Errorf("could not determine sha1 of %s: %s", releasePath, err)
+	}
+
+	for _, compiledPackage := range release.CompiledPackages {
+		parts := strings.SplitN(compiledPackage.Stemcell, "/", 2)
+		if len(parts) != 2 {
+			return BoshReleasePayload{}, fmt.Errorf("could not determine stemcell of compiled package %s: %q", compiledPackage.Name, compiledPackage.Stemcell)
+		}
+
+		boshRelease.CompiledPackages = append(boshRelease.CompiledPackages, CompiledPackage{
+			Name:            compiledPackage.Name,
+			Version:         compiledPackage.Version,
+			StemcellOS:      parts[0],
+			StemcellVersion: parts[1],
+			Dependencies:    compiledPackage.Dependencies,
+		})
+	}
 
 	matches, err = doublestar.Glob(filepath.Join(dir, "**", "jobs", "*.tgz"))
 	if err != nil {
@@ -249,6 +295,25 @@ func parseReleaseDir(releasePath string) (BoshReleasePayload, error) {
 
 	boshRelease.Name = release.Name
 	boshRelease.LatestVersion = release.Version
+	// the tarball `bosh create-release --tarball` would be expected to produce
+	boshRelease.File = fmt.Sprintf("%s-%s.tgz", release.Name, release.Version)
 
 	return boshRelease, nil
 }
+
+func fileSHA1(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha1.New()
+
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
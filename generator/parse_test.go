@@ -1,6 +1,7 @@
 package generator_test
 
 import (
+	"crypto/sha1"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -48,6 +49,8 @@ var _ = Describe("Parsing spec files", func() {
 
 		Expect(release.Name).To(Equal("my-release"))
 		Expect(release.LatestVersion).To(Equal("1.0.0"))
+		Expect(release.File).To(Equal("my-release-1.0.0.tgz"))
+		Expect(release.SHA1).To(BeEmpty())
 	})
 
 	It("parses a bosh release", func() {
@@ -56,6 +59,13 @@ var _ = Describe("Parsing spec files", func() {
 		release, err := generator.ParseRelease(dir)
 		Expect(err).NotTo(HaveOccurred())
 
+		contents, err := ioutil.ReadFile(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(release.File).To(Equal("my-release-1.0.0.tgz"))
+		Expect(release.SHA1).To(Equal(fmt.Sprintf("%x", sha1.Sum(contents))))
+		Expect(release.CompiledPackages).To(BeEmpty())
+
 		Expect(release.Name).To(Equal("my-release"))
 		Expect(release.LatestVersion).To(Equal("1.0.0"))
 
@@ -65,6 +75,46 @@ var _ = Describe("Parsing spec files", func() {
 		Expect(specs[1].Name).To(Equal("some"))
 		Expect(specs[2].Name).To(Equal("work"))
 	})
+
+	It("parses the compiled packages of a compiled bosh release", func() {
+		path := createReleaseTarballWithManifest(`
+name: my-release
+version: 1.0.0
+compiled_packages:
+- name: golang
+  version: abc123
+  fingerprint: abc123
+  sha1: def456
+  stemcell: ubuntu-xenial/621.74
+  dependencies: []
+- name: app
+  version: def789
+  fingerprint: def789
+  sha1: abc012
+  stemcell: ubuntu-xenial/621.74
+  dependencies: [golang]
+`)
+
+		release, err := generator.ParseRelease(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(release.CompiledPackages).To(Equal([]generator.CompiledPackage{
+			{
+				Name:            "golang",
+				Version:         "abc123",
+				StemcellOS:      "ubuntu-xenial",
+				StemcellVersion: "621.74",
+				Dependencies:    []string{},
+			},
+			{
+				Name:            "app",
+				Version:         "def789",
+				StemcellOS:      "ubuntu-xenial",
+				StemcellVersion: "621.74",
+				Dependencies:    []string{"golang"},
+			},
+		}))
+	})
 })
 
 func createReleaseDir() string {
@@ -95,6 +145,10 @@ func createReleaseDir() string {
 }
 
 func createReleaseTarball() string {
+	return createReleaseTarballWithManifest(`{name: my-release, version: 1.0.0}`)
+}
+
+func createReleaseTarballWithManifest(releaseMF string) string {
 	buildDir, err := ioutil.TempDir("", "")
 	Expect(err).NotTo(HaveOccurred())
 
@@ -118,7 +172,7 @@ func createReleaseTarball() string {
 		Expect(err).NotTo(HaveOccurred())
 	}
 
-	err = ioutil.WriteFile(filepath.Join(buildDir, "release.MF"), []byte(`{name: my-release, version: 1.0.0}`), os.ModePerm)
+	err = ioutil.WriteFile(filepath.Join(buildDir, "release.MF"), []byte(releaseMF), os.ModePerm)
 	Expect(err).NotTo(HaveOccurred())
 
 	releaseDir, err := ioutil.TempDir("", "")
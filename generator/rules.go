@@ -0,0 +1,198 @@
+package generator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+
+	"github.com/jtarchie/tile-builder/metadata"
+	"gopkg.in/yaml.v2"
+)
+
+// Rule sets the property blueprint of the properties it matches. Every
+// matcher that is set has to match; the first matching rule is applied,
+// before the built-in type inference.
+type Rule struct {
+	Name string
+
+	Property string `yaml:",omitempty"`
+	SpecType string `yaml:"spec_type,omitempty"`
+	Example  string `yaml:",omitempty"`
+
+	Type        string                `yaml:",omitempty"`
+	Label       string                `yaml:",omitempty"`
+	Placeholder string                `yaml:",omitempty"`
+	Constraints *metadata.Constraints `yaml:",omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+func LoadRules(path string) ([]Rule, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read rules %s: %s", path, err)
+	}
+
+	var rules []Rule
+
+	err = yaml.UnmarshalStrict(contents, &rules)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal rules %s: %s", path, err)
+	}
+
+	for index, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("rule %d in %s has no name", index, path)
+		}
+
+		if rule.Property == "" && rule.SpecType == "" && rule.Example == "" {
+			return nil, fmt.Errorf("rule %s in %s needs at least one of property, spec_type or example", rule.Name, path)
+		}
+
+		if rule.Type != "" && !metadata.ValidPropertyBlueprintType(rule.Type) {
+			return nil, fmt.Errorf("rule %s in %s has an unknown property blueprint type %q", rule.Name, path, rule.Type)
+		}
+
+		if rule.Property != "" {
+			rules[index].pattern, err = regexp.Compile(rule.Property)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s in %s has an invalid property pattern: %s", rule.Name, path, err)
+			}
+		}
+	}
+
+	return rules, nil
+}
+
+// MatchRule returns the first rule matching the property.
+func MatchRule(rules []Rule, name string, property Property) (Rule, bool) {
+	for _, rule := range rules {
+		if rule.matches(name, property) {
+			return rule, true
+		}
+	}
+
+	return Rule{}, false
+}
+
+// RuleMatch is the rule applied to the property of a job.
+type RuleMatch struct {
+	Job      string
+	Property string
+	Rule     string
+}
+
+func (r RuleMatch) String() string {
+	return fmt.Sprintf("%s of %s matched rule %s", r.Property, r.Job, r.Rule)
+}
+
+// RuleMatches reports the rule matching every property of the releases,
+// ordered by job and property.
+func RuleMatches(rules []Rule, releases ...BoshReleasePayload) []RuleMatch {
+	var matches []RuleMatch
+
+	for _, release := range releases {
+		for _, spec := range release.Specs {
+			names := []string{}
+			for name := range spec.Properties {
+				names = append(names, name)
+			}
+
+			sort.Strings(names)
+
+			for _, name := range names {
+				if rule, found := MatchRule(rules, name, spec.Properties[name]); found {
+					matches = append(matches, RuleMatch{
+						Job:      spec.Name,
+						Property: name,
+						Rule:     rule.Name,
+					})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+func (r Rule) matches(name string, property Property) bool {
+	if r.Property != "" {
+		pattern := r.pattern
+		if pattern == nil {
+			var err error
+
+			pattern, err = regexp.Compile(r.Property)
+			if err != nil {
+				return false
+			}
+		}
+
+		if !pattern.MatchString(name) {
+			return false
+		}
+	}
+
+	if r.SpecType != "" && r.SpecType != property.Type {
+		return false
+	}
+
+	if r.Example != "" && r.Example != exampleShape(property) {
+		return false
+	}
+
+	return true
+}
+
+// exampleShape describes the example, or the default when there is none, as
+// one of string, integer, boolean, list or map.
+func exampleShape(property Property) string {
+	value := property.Example
+	if value == nil {
+		value = property.Default
+	}
+
+	switch value.(type) {
+	case string:
+		return "string"
+	case int, int64, uint64, float32, float64:
+		return "integer"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case map[interface{}]interface{}:
+		return "map"
+	}
+
+	return ""
+}
+
+// applyRules annotates the properties of the releases with the rule they
+// match. The releases are copied, so the caller's specs are left untouched.
+func applyRules(rules []Rule, releases []BoshReleasePayload) []BoshReleasePayload {
+	if len(rules) == 0 {
+		return releases
+	}
+
+	annotated := make([]BoshReleasePayload, len(releases))
+	for i, release := range releases {
+		annotated[i] = release
+		annotated[i].Specs = make([]SpecPayload, len(release.Specs))
+
+		for j, spec := range release.Specs {
+			annotated[i].Specs[j] = spec
+			annotated[i].Specs[j].Properties = map[string]Property{}
+
+			for name, property := range spec.Properties {
+				if rule, found := MatchRule(rules, name, property); found {
+					property.rule = &rule
+				}
+
+				annotated[i].Specs[j].Properties[name] = property
+			}
+		}
+	}
+
+	return annotated
+}
@@ -0,0 +1,134 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rules for property blueprints", func() {
+	const rulesYAML = `
+- name: urls
+  property: _url\z
+  type: http_url
+  placeholder: https://example.com
+- name: domains
+  property: _domain\z
+  type: domain
+  label: Domain
+- name: emails
+  property: _email\z
+  type: email
+- name: cidrs
+  property: _cidr\z
+  type: network_address
+- name: mtu
+  property: \.mtu\z
+  example: integer
+  type: integer
+  constraints:
+    min: 576
+    max: 9000
+- name: bosh-passwords
+  spec_type: password
+  type: secret
+`
+
+	var rules []generator.Rule
+
+	BeforeEach(func() {
+		var err error
+
+		rules, err = generator.LoadRules(writeFile(rulesYAML))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	DescribeTable("matches the first rule", func(name string, property generator.Property, expectedRule string) {
+		rule, found := generator.MatchRule(rules, name, property)
+		if expectedRule == "" {
+			Expect(found).To(BeFalse())
+			return
+		}
+
+		Expect(found).To(BeTrue())
+		Expect(rule.Name).To(Equal(expectedRule))
+	},
+		Entry("a url", "uaa.login_url", generator.Property{}, "urls"),
+		Entry("a domain", "system_domain", generator.Property{}, "domains"),
+		Entry("an email", "smtp.from_email", generator.Property{}, "emails"),
+		Entry("a cidr", "network.allowed_cidr", generator.Property{}, "cidrs"),
+		Entry("an integer mtu", "network.mtu", generator.Property{Default: 1500}, "mtu"),
+		Entry("a string mtu", "network.mtu", generator.Property{Default: "1500"}, ""),
+		Entry("a bosh password", "admin.pass", generator.Property{Type: "password"}, "bosh-passwords"),
+		Entry("nothing", "some.property", generator.Property{}, ""),
+	)
+
+	It("applies the rule to the property blueprint, input and manifest", func() {
+		spec, err := generator.ParseSpec(writeFile(`
+name: web
+properties:
+  web.login_url:
+    description: The login page
+  web.mtu:
+    default: 1500
+  web.port:
+    default: 8080
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		tile, err := generator.Tile(generator.Options{Rules: rules}, generator.BoshReleasePayload{
+			Name:  "my-release",
+			Specs: []generator.SpecPayload{spec},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tile.PropertyBlueprints[0].Type).To(Equal("integer"))
+		Expect(tile.PropertyBlueprints[0].Constraints).To(Equal([]metadata.Constraints{{Min: 576, Max: 9000}}))
+		Expect(tile.PropertyBlueprints[1].Type).To(Equal("port"))
+		Expect(tile.PropertyBlueprints[2].Name).To(Equal("web__login_url"))
+		Expect(tile.PropertyBlueprints[2].Type).To(Equal("http_url"))
+
+		Expect(tile.FormTypes[0].PropertyInputs[2].Placeholder).To(Equal("https://example.com"))
+		Expect(tile.FormTypes[0].PropertyInputs[2].Label).To(Equal("Web Login Url"))
+
+		Expect(spec.Properties["web.login_url"]).To(Equal(generator.Property{Description: "The login page"}))
+	})
+
+	It("reports the rule matching each property", func() {
+		spec, err := generator.ParseSpec(writeFile(`
+name: web
+properties:
+  web.mtu:
+    default: 1500
+  web.login_url: {}
+  web.port:
+    default: 8080
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		matches := generator.RuleMatches(rules, generator.BoshReleasePayload{Specs: []generator.SpecPayload{spec}})
+		Expect(matches).To(Equal([]generator.RuleMatch{
+			{Job: "web", Property: "web.login_url", Rule: "urls"},
+			{Job: "web", Property: "web.mtu", Rule: "mtu"},
+		}))
+		Expect(matches[0].String()).To(Equal("web.login_url of web matched rule urls"))
+	})
+
+	It("errors on an unknown type", func() {
+		_, err := generator.LoadRules(writeFile(`[{name: typo, property: _url, type: http_uri}]`))
+		Expect(err).To(MatchError(ContainSubstring(`rule typo in`)))
+		Expect(err).To(MatchError(ContainSubstring(`has an unknown property blueprint type "http_uri"`)))
+	})
+
+	It("errors on a rule without matchers", func() {
+		_, err := generator.LoadRules(writeFile(`[{name: everything, type: string}]`))
+		Expect(err).To(MatchError(ContainSubstring("rule everything")))
+	})
+
+	It("errors on an invalid property pattern", func() {
+		_, err := generator.LoadRules(writeFile(`[{name: broken, property: "(", type: string}]`))
+		Expect(err).To(MatchError(ContainSubstring("invalid property pattern")))
+	})
+})
@@ -0,0 +1,294 @@
+package generator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jtarchie/tile-builder/metadata"
+	"gopkg.in/yaml.v2"
+)
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// selector replaces a group of mutually exclusive properties sharing the
+// prefix name, e.g. `tls.enabled`, `tls.certificate` and `tls.ciphers`,
+// with a single selector property blueprint.
+type selector struct {
+	name         string
+	description  string
+	defaultValue string
+	options      []selectorOption
+	properties   map[string]bool
+}
+
+type selectorOption struct {
+	name        string
+	selectValue string
+	// values is the part of the manifest that selects the option, e.g. `enabled: true`
+	values map[string]interface{}
+	// root is the prefix of the properties only configured when the option is selected
+	root       string
+	properties []string
+}
+
+type selectors []selector
+
+func (s selectors) forProperty(name string) (selector, bool) {
+	for _, selector := range s {
+		if selector.properties[name] {
+			return selector, true
+		}
+	}
+
+	return selector{}, false
+}
+
+func (s selectors) named(name string) (selector, bool) {
+	for _, selector := range s {
+		if selector.name == name {
+			return selector, true
+		}
+	}
+
+	return selector{}, false
+}
+
+// detectSelectors recognizes two patterns in the properties of a release.
+// A `prefix.enabled` boolean with sibling properties becomes a selector
+// between an enabled option, configuring the siblings, and a disabled one.
+// A `prefix.type` property with a list of examples becomes a selector with an
+// option per example, configuring the properties under `prefix.<example>`.
+// It only applies when every sibling belongs to an option, as a shared
+// sibling could not be rendered next to the selected option's manifest.
+//
+// Selectors are detected from the shortest prefix first, so a nested pattern
+// becomes part of the enclosing selector's option.
+func detectSelectors(properties map[string]Property) selectors {
+	names := []string{}
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if strings.Count(names[i], ".") != strings.Count(names[j], ".") {
+			return strings.Count(names[i], ".") < strings.Count(names[j], ".")
+		}
+
+		return names[i] < names[j]
+	})
+
+	var detected selectors
+
+	for _, name := range names {
+		if _, found := detected.forProperty(name); found {
+			continue
+		}
+
+		index := strings.LastIndex(name, ".")
+		if index < 0 {
+			continue
+		}
+
+		prefix, key := name[:index], name[index+1:]
+		if _, found := properties[prefix]; found {
+			continue
+		}
+
+		var siblings []string
+		for _, other := range names {
+			if other != name && strings.HasPrefix(other, prefix+".") {
+				siblings = append(siblings, other)
+			}
+		}
+
+		if len(siblings) == 0 {
+			continue
+		}
+
+		property := properties[name]
+
+		switch key {
+		case "enabled":
+			if selector, ok := enabledSelector(prefix, name, property, siblings); ok {
+				detected = append(detected, selector)
+			}
+		case "type":
+			if selector, ok := typeSelector(prefix, name, property, siblings); ok {
+				detected = append(detected, selector)
+			}
+		}
+	}
+
+	return detected
+}
+
+func enabledSelector(prefix, name string, property Property, siblings []string) (selector, bool) {
+	pbType, err := DeterminePropertyBlueprintType(name, property)
+	if err != nil || pbType != "boolean" {
+		return selector{}, false
+	}
+
+	s := selector{
+		name:         prefix,
+		description:  property.Description,
+		defaultValue: "disabled",
+		properties:   map[string]bool{name: true},
+		options: []selectorOption{
+			{
+				name:        "enabled",
+				selectValue: "enabled",
+				values:      map[string]interface{}{"enabled": true},
+				root:        prefix,
+				properties:  siblings,
+			},
+			{
+				name:        "disabled",
+				selectValue: "disabled",
+				values:      map[string]interface{}{"enabled": false},
+			},
+		},
+	}
+
+	if enabled, ok := property.Default.(bool); ok && enabled {
+		s.defaultValue = "enabled"
+	}
+
+	for _, sibling := range siblings {
+		s.properties[sibling] = true
+	}
+
+	return s, true
+}
+
+func typeSelector(prefix, name string, property Property, siblings []string) (selector, bool) {
+	examples, ok := property.Example.([]interface{})
+	if !ok || len(examples) < 2 {
+		return selector{}, false
+	}
+
+	s := selector{
+		name:        prefix,
+		description: property.Description,
+		properties:  map[string]bool{name: true},
+	}
+
+	for _, example := range examples {
+		value, ok := example.(string)
+		if !ok {
+			return selector{}, false
+		}
+
+		s.options = append(s.options, selectorOption{
+			name:        nonIdentifier.ReplaceAllString(strings.ToLower(value), "_"),
+			selectValue: value,
+			values:      map[string]interface{}{"type": value},
+			root:        fmt.Sprintf("%s.%s", prefix, value),
+		})
+
+		if value == property.Default {
+			s.defaultValue = value
+		}
+	}
+
+	if s.defaultValue == "" {
+		s.defaultValue = s.options[0].selectValue
+	}
+
+	for _, sibling := range siblings {
+		belongs := false
+
+		for i, option := range s.options {
+			if strings.HasPrefix(sibling, option.root+".") {
+				s.options[i].properties = append(s.options[i].properties, sibling)
+				belongs = true
+				break
+			}
+		}
+
+		if !belongs {
+			return selector{}, false
+		}
+
+		s.properties[sibling] = true
+	}
+
+	return s, true
+}
+
+// create builds the selector property blueprint, and the property input
+// with an input per option.
+func (s selector) create(properties map[string]Property, scope scope) (metadata.PropertyInput, metadata.PropertyBlueprint, error) {
+	blueprintName := scope.blueprintName(s.name)
+
+	propertyInput := metadata.PropertyInput{
+		Reference:   scope.reference(s.name),
+		Label:       strings.Title(breakApartName(s.name)),
+		Description: s.description,
+	}
+
+	propertyBlueprint := metadata.PropertyBlueprint{
+		Name:         blueprintName,
+		Type:         "selector",
+		Configurable: true,
+		Default:      s.defaultValue,
+	}
+
+	for _, option := range s.options {
+		optionReference := fmt.Sprintf("%s.%s", propertyInput.Reference, option.name)
+
+		selectorInput := metadata.PropertyInput{
+			Reference: optionReference,
+			Label:     strings.Title(breakApartName(option.selectValue)),
+		}
+
+		manifest := map[string]interface{}{}
+		for key, value := range option.values {
+			manifest[key] = value
+		}
+
+		optionTemplate := metadata.OptionTemplate{
+			Name:        option.name,
+			SelectValue: option.selectValue,
+		}
+
+		sort.Strings(option.properties)
+
+		for _, name := range option.properties {
+			property := properties[name]
+			relativeName := strings.TrimPrefix(name, option.root+".")
+
+			propertyBlueprint, err := createPropertyBlueprint(property, relativeName)
+			if err != nil {
+				return metadata.PropertyInput{}, metadata.PropertyBlueprint{}, err
+			}
+			optionTemplate.PropertyBlueprints = append(optionTemplate.PropertyBlueprints, propertyBlueprint)
+
+			selectorInput.PropertyInputs = append(selectorInput.PropertyInputs, propertyInputFor(property, relativeName, fmt.Sprintf("%s.%s", optionReference, propertyBlueprint.Name)))
+
+			value, err := manifestFromProperty(fmt.Sprintf("%s.%s", optionReference, propertyBlueprint.Name), relativeName, property)
+			if err != nil {
+				return metadata.PropertyInput{}, metadata.PropertyBlueprint{}, fmt.Errorf("could not create manifest for property %s: %s", name, err)
+			}
+			setManifestValue(manifest, strings.TrimPrefix(name, s.name+"."), value)
+		}
+
+		contents, err := yaml.Marshal(manifest)
+		if err != nil {
+			return metadata.PropertyInput{}, metadata.PropertyBlueprint{}, fmt.Errorf("could not marshal manifest of option %s: %s", option.name, err)
+		}
+
+		optionTemplate.NamedManifests = []metadata.NamedManifest{
+			{
+				Name:     "manifest",
+				Manifest: string(contents),
+			},
+		}
+
+		propertyBlueprint.OptionTemplates = append(propertyBlueprint.OptionTemplates, optionTemplate)
+		propertyInput.SelectorPropertyInputs = append(propertyInput.SelectorPropertyInputs, selectorInput)
+	}
+
+	return propertyInput, propertyBlueprint, nil
+}
@@ -0,0 +1,159 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generating selectors", func() {
+	generate := func(spec string) metadata.Payload {
+		payload, err := generator.ParseSpec(writeFile(spec))
+		Expect(err).NotTo(HaveOccurred())
+
+		tile, err := generator.Tile(generator.Options{}, generator.BoshReleasePayload{
+			Name:  "my-release",
+			Specs: []generator.SpecPayload{payload},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return tile
+	}
+
+	It("creates a selector from an enabled boolean with siblings", func() {
+		tile := generate(`
+name: web
+properties:
+  web.tls.enabled:
+    description: Enable TLS
+    default: false
+  web.tls.certificate:
+    type: certificate
+  web.tls.ciphers:
+    default: ECDHE-RSA-AES128-GCM-SHA256
+  web.port:
+    default: 8080
+`)
+
+		Expect(tile.PropertyBlueprints).To(HaveLen(2))
+		Expect(tile.PropertyBlueprints[0].Name).To(Equal("web__port"))
+
+		pb := tile.PropertyBlueprints[1]
+		Expect(pb.Name).To(Equal("web__tls"))
+		Expect(pb.Type).To(Equal("selector"))
+		Expect(pb.Default).To(Equal("disabled"))
+		Expect(pb.OptionTemplates).To(HaveLen(2))
+
+		enabled := pb.OptionTemplates[0]
+		Expect(enabled.Name).To(Equal("enabled"))
+		Expect(enabled.SelectValue).To(Equal("enabled"))
+		Expect(enabled.PropertyBlueprints[0].Name).To(Equal("certificate"))
+		Expect(enabled.PropertyBlueprints[0].Type).To(Equal("rsa_cert_credentials"))
+		Expect(enabled.PropertyBlueprints[1].Name).To(Equal("ciphers"))
+		Expect(enabled.PropertyBlueprints[1].Default).To(Equal("ECDHE-RSA-AES128-GCM-SHA256"))
+		Expect(enabled.NamedManifests[0].Name).To(Equal("manifest"))
+		Expect(enabled.NamedManifests[0].Manifest).To(MatchYAML(`
+enabled: true
+certificate:
+  certificate: ((.properties.web__tls.enabled.certificate.certificate))
+  private_key: ((.properties.web__tls.enabled.certificate.private_key))
+ciphers: ((.properties.web__tls.enabled.ciphers.value))
+`))
+
+		disabled := pb.OptionTemplates[1]
+		Expect(disabled.Name).To(Equal("disabled"))
+		Expect(disabled.PropertyBlueprints).To(BeEmpty())
+		Expect(disabled.NamedManifests[0].Manifest).To(MatchYAML(`enabled: false`))
+
+		input := tile.FormTypes[0].PropertyInputs[1]
+		Expect(input.Reference).To(Equal(".properties.web__tls"))
+		Expect(input.Label).To(Equal("Web Tls"))
+		Expect(input.Description).To(Equal("Enable TLS"))
+		Expect(input.SelectorPropertyInputs).To(Equal([]metadata.PropertyInput{
+			{
+				Reference: ".properties.web__tls.enabled",
+				Label:     "Enabled",
+				PropertyInputs: []metadata.PropertyInput{
+					{Reference: ".properties.web__tls.enabled.certificate", Label: "Certificate"},
+					{Reference: ".properties.web__tls.enabled.ciphers", Label: "Ciphers"},
+				},
+			},
+			{
+				Reference: ".properties.web__tls.disabled",
+				Label:     "Disabled",
+			},
+		}))
+
+		Expect(tile.JobTypes[0].Manifest).To(MatchYAML(`
+web:
+  port: ((.properties.web__port.value))
+  tls: ((.properties.web__tls.selected_option.parsed_manifest(manifest)))
+`))
+
+		validations, err := tile.Validate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validations).NotTo(HaveKey(ContainSubstring("PropertyBlueprints[1]")))
+		Expect(validations).NotTo(HaveKey(ContainSubstring("FormTypes[0].PropertyInputs")))
+	})
+
+	It("creates a selector from a type with enumerated examples", func() {
+		tile := generate(`
+name: blobstore
+properties:
+  blobstore.type:
+    default: local
+    example: [local, s3]
+  blobstore.local.path:
+    default: /var/vcap/store
+  blobstore.s3.bucket:
+    description: The bucket
+  blobstore.s3.region:
+    default: us-east-1
+`)
+
+		Expect(tile.PropertyBlueprints).To(HaveLen(1))
+
+		pb := tile.PropertyBlueprints[0]
+		Expect(pb.Name).To(Equal("blobstore"))
+		Expect(pb.Type).To(Equal("selector"))
+		Expect(pb.Default).To(Equal("local"))
+
+		Expect(pb.OptionTemplates[0].Name).To(Equal("local"))
+		Expect(pb.OptionTemplates[0].PropertyBlueprints[0].Name).To(Equal("path"))
+		Expect(pb.OptionTemplates[0].NamedManifests[0].Manifest).To(MatchYAML(`
+type: local
+local:
+  path: ((.properties.blobstore.local.path.value))
+`))
+
+		Expect(pb.OptionTemplates[1].Name).To(Equal("s3"))
+		Expect(pb.OptionTemplates[1].SelectValue).To(Equal("s3"))
+		Expect(pb.OptionTemplates[1].NamedManifests[0].Manifest).To(MatchYAML(`
+type: s3
+s3:
+  bucket: ((.properties.blobstore.s3.bucket.value))
+  region: ((.properties.blobstore.s3.region.value))
+`))
+
+		Expect(tile.JobTypes[0].Manifest).To(MatchYAML(`
+blobstore: ((.properties.blobstore.selected_option.parsed_manifest(manifest)))
+`))
+	})
+
+	It("leaves a type with settings shared between examples as properties", func() {
+		tile := generate(`
+name: blobstore
+properties:
+  blobstore.type:
+    default: local
+    example: [local, s3]
+  blobstore.timeout:
+    default: 30
+`)
+
+		Expect(tile.PropertyBlueprints).To(HaveLen(2))
+		Expect(tile.PropertyBlueprints[0].Type).To(Equal("integer"))
+		Expect(tile.PropertyBlueprints[1].Type).To(Equal("string"))
+	})
+})
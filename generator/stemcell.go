@@ -0,0 +1,53 @@
+package generator
+
+import (
+	"fmt"
+
+	"github.com/jtarchie/tile-builder/metadata"
+)
+
+// StemcellCriteria pins the tile to the stemcell the first compiled release
+// was compiled against. A warning is returned for every release with packages
+// compiled against a different stemcell, as they will not be usable on the
+// pinned one.
+func StemcellCriteria(releases ...BoshReleasePayload) (metadata.StemcellCriteria, []string) {
+	var (
+		criteria metadata.StemcellCriteria
+		warnings []string
+	)
+
+	for _, release := range releases {
+		var stemcells []string
+		mismatched := map[string]int{}
+
+		for _, compiledPackage := range release.CompiledPackages {
+			if criteria.OS == "" {
+				criteria.OS = compiledPackage.StemcellOS
+				criteria.Version = compiledPackage.StemcellVersion
+				continue
+			}
+
+			if compiledPackage.StemcellOS != criteria.OS || compiledPackage.StemcellVersion != criteria.Version {
+				stemcell := fmt.Sprintf("%s/%s", compiledPackage.StemcellOS, compiledPackage.StemcellVersion)
+				if mismatched[stemcell] == 0 {
+					stemcells = append(stemcells, stemcell)
+				}
+
+				mismatched[stemcell]++
+			}
+		}
+
+		for _, stemcell := range stemcells {
+			warnings = append(warnings, fmt.Sprintf(
+				"%d package(s) of release %s are compiled against stemcell %s, but the tile is pinned to stemcell %s/%s",
+				mismatched[stemcell],
+				release.Name,
+				stemcell,
+				criteria.OS,
+				criteria.Version,
+			))
+		}
+	}
+
+	return criteria, warnings
+}
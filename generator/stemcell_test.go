@@ -0,0 +1,53 @@
+package generator_test
+
+import (
+	"github.com/jtarchie/tile-builder/generator"
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Determining the stemcell criteria", func() {
+	It("is empty when no release is compiled", func() {
+		criteria, warnings := generator.StemcellCriteria(generator.BoshReleasePayload{Name: "source"})
+		Expect(criteria).To(Equal(metadata.StemcellCriteria{}))
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("pins to the stemcell of the compiled release", func() {
+		criteria, warnings := generator.StemcellCriteria(
+			generator.BoshReleasePayload{Name: "source"},
+			generator.BoshReleasePayload{
+				Name: "compiled",
+				CompiledPackages: []generator.CompiledPackage{
+					{Name: "golang", StemcellOS: "ubuntu-xenial", StemcellVersion: "621.74"},
+				},
+			},
+		)
+		Expect(criteria).To(Equal(metadata.StemcellCriteria{OS: "ubuntu-xenial", Version: "621.74"}))
+		Expect(warnings).To(BeEmpty())
+	})
+
+	It("warns when releases are compiled against different stemcells", func() {
+		criteria, warnings := generator.StemcellCriteria(
+			generator.BoshReleasePayload{
+				Name: "first",
+				CompiledPackages: []generator.CompiledPackage{
+					{Name: "golang", StemcellOS: "ubuntu-xenial", StemcellVersion: "621.74"},
+				},
+			},
+			generator.BoshReleasePayload{
+				Name: "second",
+				CompiledPackages: []generator.CompiledPackage{
+					{Name: "ruby", StemcellOS: "ubuntu-bionic", StemcellVersion: "1.10"},
+					{Name: "nginx", StemcellOS: "ubuntu-bionic", StemcellVersion: "1.10"},
+					{Name: "golang", StemcellOS: "ubuntu-xenial", StemcellVersion: "621.74"},
+				},
+			},
+		)
+		Expect(criteria).To(Equal(metadata.StemcellCriteria{OS: "ubuntu-xenial", Version: "621.74"}))
+		Expect(warnings).To(Equal([]string{
+			"2 package(s) of release second are compiled against stemcell ubuntu-bionic/1.10, but the tile is pinned to stemcell ubuntu-xenial/621.74",
+		}))
+	})
+})
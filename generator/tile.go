@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/jtarchie/tile-builder/metadata"
@@ -11,83 +12,250 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-func Tile(release BoshReleasePayload) (metadata.Payload, error) {
-	specs := release.Specs
+type Options struct {
+	// CrossDeploymentLinks maps the name of a consumed link to the product
+	// that provides it from another deployment.
+	CrossDeploymentLinks map[string]string
+	// Rules are applied before the built-in type inference.
+	Rules []Rule
+	// JobScoped creates the property blueprints of each job on its job type,
+	// referenced as `.job_name.property`, instead of in `.properties`.
+	JobScoped bool
+	Grouping  Grouping
+}
 
+func Tile(options Options, releases ...BoshReleasePayload) (metadata.Payload, error) {
 	var t metadata.Payload
 
-	propertiesByGroup := map[string]map[string]Property{}
+	releases = applyRules(options.Rules, releases)
 
-	for _, payload := range specs {
-		for name, property := range payload.Properties {
-			parts := strings.Split(name, ".")
+	jobReleases := map[string]string{}
+	for _, release := range releases {
+		for _, spec := range release.Specs {
+			if other, found := jobReleases[spec.Name]; found {
+				return metadata.Payload{}, fmt.Errorf("job %s is defined in both release %s and release %s", spec.Name, other, release.Name)
+			}
+			jobReleases[spec.Name] = release.Name
+		}
+	}
+
+	for _, release := range releases {
+		t.Releases = append(t.Releases, metadata.Release{
+			Name:    release.Name,
+			Version: release.LatestVersion,
+			File:    release.File,
+			SHA1:    release.SHA1,
+		})
+	}
+
+	t.StemcellCriteria, _ = StemcellCriteria(releases...)
+
+	shared := sharedProperties(releases, options)
+
+	releaseSelectors := map[string]selectors{}
+
+	if !options.JobScoped {
+		for _, release := range releases {
+			scope := propertyScope(release, releases, "", options)
+			properties := collectProperties(release.Specs, scope, shared)
+			releaseSelectors[release.Name] = detectSelectors(properties)
+
+			formTypes, propertyBlueprints, err := createForms(properties, scope, releaseSelectors[release.Name], newFormGroups(options.Grouping, scope.prefix, release.Specs, false))
+			if err != nil {
+				return metadata.Payload{}, err
+			}
+
+			t.FormTypes = append(t.FormTypes, formTypes...)
+			t.PropertyBlueprints = append(t.PropertyBlueprints, propertyBlueprints...)
+		}
+	}
 
-			group := "properties"
-			if len(parts) > 1 {
-				group = parts[0]
+	for _, release := range releases {
+		for _, spec := range release.Specs {
+			scope := propertyScope(release, releases, spec.Name, options)
+			selectors := releaseSelectors[release.Name]
+
+			var (
+				formTypes          []metadata.FormType
+				propertyBlueprints []metadata.PropertyBlueprint
+				err                error
+			)
+
+			if options.JobScoped {
+				properties := collectProperties([]SpecPayload{spec}, scope, shared)
+				selectors = detectSelectors(properties)
+
+				formTypes, propertyBlueprints, err = createForms(properties, scope, selectors, newFormGroups(options.Grouping, spec.Name, []SpecPayload{spec}, true))
+				if err != nil {
+					return metadata.Payload{}, err
+				}
+			}
+
+			jobType, err := createJob(spec, release, releases, scope, shared, selectors, options)
+			if err != nil {
+				return metadata.Payload{}, err
 			}
+			jobType.PropertyBlueprints = propertyBlueprints
+
+			t.FormTypes = append(t.FormTypes, formTypes...)
+			t.JobTypes = append(t.JobTypes, jobType)
+		}
+	}
+
+	return t, nil
+}
+
+// scope is where the property blueprints of a set of properties are created,
+// either the tile's `.properties` or the property blueprints of a job.
+type scope struct {
+	root   string
+	prefix string
+}
+
+func propertyScope(release BoshReleasePayload, releases []BoshReleasePayload, jobName string, options Options) scope {
+	if options.JobScoped {
+		return scope{root: fmt.Sprintf(".%s", jobName)}
+	}
+
+	return scope{root: ".properties", prefix: releasePrefix(release, releases)}
+}
+
+func (s scope) blueprintName(name string) string {
+	return propertyBlueprintNameFromPropertyName(qualifiedPropertyName(s.prefix, name))
+}
+
+func (s scope) reference(name string) string {
+	return fmt.Sprintf("%s.%s", s.root, s.blueprintName(name))
+}
 
-			if propertiesByGroup[group] == nil {
-				propertiesByGroup[group] = map[string]Property{}
+// releasePrefix namespaces the forms and property blueprints of a release
+// when the tile is built from more than one, so their names cannot collide.
+func releasePrefix(release BoshReleasePayload, releases []BoshReleasePayload) string {
+	if len(releases) < 2 {
+		return ""
+	}
+
+	return strings.Replace(release.Name, "-", "_", -1)
+}
+
+func qualifiedPropertyName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s.%s", prefix, name)
+}
+
+// collectProperties collects the properties of the jobs, except those
+// configured by the property blueprint of a link provider.
+func collectProperties(specs []SpecPayload, scope scope, shared map[string]map[string]string) map[string]Property {
+	properties := map[string]Property{}
+
+	for _, payload := range specs {
+		for name, property := range payload.Properties {
+			if reference, found := shared[payload.Name][name]; found && reference != scope.reference(name) {
+				continue
 			}
 
-			propertiesByGroup[group][name] = property
+			properties[name] = property
+		}
+	}
+
+	return properties
+}
+
+// createForms creates a form per group of properties. Required inputs are
+// sorted before the optional ones.
+func createForms(properties map[string]Property, scope scope, selectors selectors, groups formGroups) ([]metadata.FormType, []metadata.PropertyBlueprint, error) {
+	var (
+		formTypes          []metadata.FormType
+		propertyBlueprints []metadata.PropertyBlueprint
+	)
+
+	namesByForm := map[string][]string{}
+
+	for name := range properties {
+		if _, found := selectors.forProperty(name); !found {
+			namesByForm[groups.name(name)] = append(namesByForm[groups.name(name)], name)
 		}
 	}
 
-	groupNames := []string{}
-	for group := range propertiesByGroup {
-		groupNames = append(groupNames, group)
+	for _, selector := range selectors {
+		namesByForm[groups.name(selector.name)] = append(namesByForm[groups.name(selector.name)], selector.name)
+	}
+
+	groups.merge(namesByForm)
+
+	formNames := []string{}
+	for form := range namesByForm {
+		formNames = append(formNames, form)
 	}
 
-	sort.Strings(groupNames)
+	sort.Strings(formNames)
 
-	for _, group := range groupNames {
+	for _, form := range formNames {
 		var ft metadata.FormType
-		ft.Name = group
-		ft.Label = strings.Title(breakApartName(group))
+		ft.Name = form
+		ft.Label = groups.label(form)
 		ft.Description = fmt.Sprintf("Configuration settings for %s", ft.Label)
 
-		propertyNames := []string{}
-		for name := range propertiesByGroup[group] {
-			propertyNames = append(propertyNames, name)
-		}
+		var (
+			inputs     []metadata.PropertyInput
+			blueprints []metadata.PropertyBlueprint
+		)
 
+		propertyNames := namesByForm[form]
 		sort.Strings(propertyNames)
 
 		for _, name := range propertyNames {
-			property := propertiesByGroup[group][name]
+			if selector, found := selectors.named(name); found {
+				propertyInput, propertyBlueprint, err := selector.create(properties, scope)
+				if err != nil {
+					return nil, nil, err
+				}
 
-			createPropertyInput(property, name, &ft)
+				inputs = append(inputs, propertyInput)
+				blueprints = append(blueprints, propertyBlueprint)
+				continue
+			}
 
-			propertyBlueprint, err := createPropertyBlueprint(property, name)
+			property := properties[name]
+
+			propertyBlueprint, err := createPropertyBlueprint(property, qualifiedPropertyName(scope.prefix, name))
 			if err != nil {
-				return metadata.Payload{}, err
+				return nil, nil, err
 			}
 
-			t.PropertyBlueprints = append(t.PropertyBlueprints, propertyBlueprint)
+			inputs = append(inputs, propertyInputFor(property, name, scope.reference(name)))
+			blueprints = append(blueprints, propertyBlueprint)
 		}
 
-		t.FormTypes = append(t.FormTypes, ft)
-	}
+		order := make([]int, len(inputs))
+		for i := range order {
+			order[i] = i
+		}
 
-	for _, spec := range specs {
-		jobType, err := createJob(spec, release)
-		if err != nil {
-			return metadata.Payload{}, err
+		sort.SliceStable(order, func(i, j int) bool {
+			return !blueprints[order[i]].Optional && blueprints[order[j]].Optional
+		})
+
+		for _, i := range order {
+			ft.PropertyInputs = append(ft.PropertyInputs, inputs[i])
+			propertyBlueprints = append(propertyBlueprints, blueprints[i])
 		}
-		t.JobTypes = append(t.JobTypes, jobType)
+
+		formTypes = append(formTypes, ft)
 	}
 
-	return t, nil
+	return formTypes, propertyBlueprints, nil
 }
 
-func createJob(spec SpecPayload, release BoshReleasePayload) (metadata.JobType, error) {
+func createJob(spec SpecPayload, release BoshReleasePayload, releases []BoshReleasePayload, scope scope, shared map[string]map[string]string, selectors selectors, options Options) (metadata.JobType, error) {
 	var jobType metadata.JobType
 
 	jobType.Name = spec.Name
 	jobType.ResourceLabel = strings.Title(breakApartName(spec.Name))
-	templates, err := generateTemplateForSpec(release, spec)
+	templates, err := generateTemplateForSpec(release, releases, spec, options)
 	if err != nil {
 		return metadata.JobType{}, err
 	}
@@ -100,21 +268,22 @@ func createJob(spec SpecPayload, release BoshReleasePayload) (metadata.JobType,
 
 	manifest := map[string]interface{}{}
 	for name, property := range spec.Properties {
-		parts := strings.Split(name, ".")
-
-		root := manifest
-		for i := 0; i < len(parts)-1; i++ {
-			part := parts[i]
-			if _, ok := root[part]; !ok {
-				root[part] = map[string]interface{}{}
-			}
-			root = root[part].(map[string]interface{})
+		reference := scope.reference(name)
+		if sharedReference, found := shared[spec.Name][name]; found {
+			reference = sharedReference
+		} else if selector, found := selectors.forProperty(name); found {
+			setManifestValue(manifest, selector.name, fmt.Sprintf(
+				"((%s.selected_option.parsed_manifest(manifest)))",
+				scope.reference(selector.name),
+			))
+			continue
 		}
-		option, err := CreateManifestFromProperty(name, property)
+
+		option, err := manifestFromProperty(reference, name, property)
 		if err != nil {
 			return metadata.JobType{}, fmt.Errorf("could not create manifest for property %s: %s", name, err)
 		}
-		root[parts[len(parts)-1]] = option
+		setManifestValue(manifest, name, option)
 	}
 
 	manifestYAML, err := yaml.Marshal(manifest)
@@ -126,12 +295,20 @@ func createJob(spec SpecPayload, release BoshReleasePayload) (metadata.JobType,
 	return jobType, nil
 }
 
-func createPropertyInput(property Property, name string, ft *metadata.FormType) {
+func propertyInputFor(property Property, name, reference string) metadata.PropertyInput {
 	var propertyInput metadata.PropertyInput
 	propertyInput.Description = property.Description
 	propertyInput.Label = strings.Title(breakApartName(name))
-	propertyInput.Reference = fmt.Sprintf(".properties.%s", propertyBlueprintNameFromPropertyName(name))
-	ft.PropertyInputs = append(ft.PropertyInputs, propertyInput)
+	propertyInput.Reference = reference
+
+	if property.rule != nil {
+		if property.rule.Label != "" {
+			propertyInput.Label = property.rule.Label
+		}
+		propertyInput.Placeholder = property.rule.Placeholder
+	}
+
+	return propertyInput
 }
 
 func createPropertyBlueprint(property Property, name string) (metadata.PropertyBlueprint, error) {
@@ -155,20 +332,14 @@ func createPropertyBlueprint(property Property, name string) (metadata.PropertyB
 
 	propertyBlueprint.Type = pbType
 
+	if property.rule != nil && property.rule.Constraints != nil {
+		propertyBlueprint.Constraints = []metadata.Constraints{*property.rule.Constraints}
+	}
+
 	if propertyBlueprint.Type == "collection" {
-		propertyBlueprint.PropertyBlueprints = []metadata.PropertyBlueprint{
-			{
-				Name:         "key",
-				Type:         "string",
-				Optional:     true,
-				Configurable: true,
-			},
-			{
-				Name:         "value",
-				Type:         "string",
-				Optional:     true,
-				Configurable: true,
-			},
+		propertyBlueprint.PropertyBlueprints, err = collectionBlueprints(name, property)
+		if err != nil {
+			return metadata.PropertyBlueprint{}, err
 		}
 	}
 
@@ -177,23 +348,96 @@ func createPropertyBlueprint(property Property, name string) (metadata.PropertyB
 
 func DeterminePropertyBlueprintDefault(name string, property Property) (interface{}, error) {
 	switch v := property.Default.(type) {
-	case int, float32, float64:
+	case int, int64, uint64, float32, float64:
 		return v, nil
 	case nil, string:
 		return v, nil
 	case bool:
 		return v, nil
 	case []interface{}:
+		if isCollection(name, property) {
+			if entries, ok := listOfMaps(v); ok {
+				blueprints, err := collectionBlueprints(name, property)
+				if err != nil {
+					return nil, err
+				}
+
+				return collectionEntries(name, entries, blueprints)
+			}
+		}
+
 		list := []string{}
 		for _, item := range v {
-			list = append(list, fmt.Sprintf("%s", item))
+			value, err := formatDefault(item)
+			if err != nil {
+				return nil, fmt.Errorf("could not determine default for %s: %s", name, err)
+			}
+
+			list = append(list, value)
 		}
 
 		return strings.Join(list, ","), nil
 	case map[interface{}]interface{}:
-		return nil, nil
+		keys := []string{}
+		values := map[string]interface{}{}
+		for key, value := range v {
+			keys = append(keys, fmt.Sprintf("%v", key))
+			values[fmt.Sprintf("%v", key)] = value
+		}
+
+		sort.Strings(keys)
+
+		entries := []map[string]interface{}{}
+		for _, key := range keys {
+			value, err := formatDefault(values[key])
+			if err != nil {
+				return nil, fmt.Errorf("could not determine default for %s: %s", name, err)
+			}
+
+			entries = append(entries, map[string]interface{}{
+				"key":   key,
+				"value": value,
+			})
+		}
+
+		return entries, nil
+	}
+	return nil, fmt.Errorf("could not determine default for %s of %T", name, property.Default)
+}
+
+func isCollection(name string, property Property) bool {
+	pbType, err := DeterminePropertyBlueprintType(name, property)
+	return err == nil && pbType == "collection"
+}
+
+// formatDefault formats a value for a property blueprint that can only hold
+// a string. Structured values are formatted as YAML.
+func formatDefault(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+
+	contents, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("could not format %T: %s", value, err)
 	}
-	return nil, fmt.Errorf("could not determine default for %s of %t", name, property.Default)
+
+	return strings.TrimSuffix(string(contents), "\n"), nil
 }
 
 func propertyBlueprintNameFromPropertyName(name string) string {
@@ -201,21 +445,52 @@ func propertyBlueprintNameFromPropertyName(name string) string {
 }
 
 func CreateManifestFromProperty(name string, property Property) (interface{}, error) {
+	return manifestFromProperty(fmt.Sprintf(".properties.%s", propertyBlueprintNameFromPropertyName(name)), name, property)
+}
+
+// manifestFromProperty renders the accessors of the property blueprint at
+// reference, e.g. `.properties.some__property`.
+func manifestFromProperty(reference, name string, property Property) (interface{}, error) {
 	pbType, err := DeterminePropertyBlueprintType(name, property)
 	if err != nil {
 		return nil, err
 	}
 
-	propertyBlueprintName := propertyBlueprintNameFromPropertyName(name)
-
 	switch pbType {
 	case "rsa_cert_credentials":
 		return map[string]string{
-			"certificate": fmt.Sprintf("((.properties.%s.certificate))", propertyBlueprintName),
-			"private_key": fmt.Sprintf("((.properties.%s.private_key))", propertyBlueprintName),
+			"certificate": fmt.Sprintf("((%s.certificate))", reference),
+			"private_key": fmt.Sprintf("((%s.private_key))", reference),
 		}, nil
+	case "rsa_pkey_credentials":
+		return map[string]string{
+			"public_key":  fmt.Sprintf("((%s.public_key))", reference),
+			"private_key": fmt.Sprintf("((%s.private_key))", reference),
+		}, nil
+	case "simple_credentials":
+		return map[string]string{
+			"identity": fmt.Sprintf("((%s.identity))", reference),
+			"password": fmt.Sprintf("((%s.password))", reference),
+		}, nil
+	case "secret":
+		return fmt.Sprintf("((%s.secret))", reference), nil
 	}
-	return fmt.Sprintf("((.properties.%s.value))", propertyBlueprintName), nil
+	return fmt.Sprintf("((%s.value))", reference), nil
+}
+
+// setManifestValue sets the value of a dotted property name in a nested manifest.
+func setManifestValue(manifest map[string]interface{}, name string, value interface{}) {
+	parts := strings.Split(name, ".")
+
+	root := manifest
+	for i := 0; i < len(parts)-1; i++ {
+		part := parts[i]
+		if _, ok := root[part].(map[string]interface{}); !ok {
+			root[part] = map[string]interface{}{}
+		}
+		root = root[part].(map[string]interface{})
+	}
+	root[parts[len(parts)-1]] = value
 }
 
 func attachResourceDefinitions(jobType *metadata.JobType) {
@@ -277,14 +552,15 @@ func attachInstanceDefinition(jobType *metadata.JobType) {
 }
 
 type consumer struct {
-	From string
+	From       string
+	Deployment string `yaml:",omitempty"`
 }
 
 type provider struct {
 	As string
 }
 
-func generateTemplateForSpec(release BoshReleasePayload, spec SpecPayload) ([]metadata.Template, error) {
+func generateTemplateForSpec(release BoshReleasePayload, releases []BoshReleasePayload, spec SpecPayload, options Options) ([]metadata.Template, error) {
 	var template metadata.Template
 
 	template.Name = spec.Name
@@ -292,7 +568,7 @@ func generateTemplateForSpec(release BoshReleasePayload, spec SpecPayload) ([]me
 
 	consuming := map[string]consumer{}
 	for _, consume := range spec.Consumes {
-		generateConsumer(release, spec, consume, consuming)
+		generateConsumer(releases, spec, consume, consuming, options)
 	}
 
 	contents, err := yaml.Marshal(consuming)
@@ -317,24 +593,30 @@ func generateTemplateForSpec(release BoshReleasePayload, spec SpecPayload) ([]me
 	return []metadata.Template{template}, nil
 }
 
-func generateConsumer(release BoshReleasePayload, payload SpecPayload, consume consumePayload, consuming map[string]consumer) {
-	for _, spec := range release.Specs {
-		if spec.Name == payload.Name {
-			continue
+func generateConsumer(releases []BoshReleasePayload, payload SpecPayload, consume consumePayload, consuming map[string]consumer, options Options) {
+	if provider, found := findProvider(releases, payload, consume); found {
+		consuming[consume.Name] = consumer{
+			From: fmt.Sprintf("%s-%s", provider.spec.Name, consume.Name),
 		}
+		return
+	}
 
-		for _, provide := range spec.Provides {
-			if provide.Name == consume.Name && provide.Type == consume.Type {
-				consuming[consume.Name] = consumer{
-					From: fmt.Sprintf("%s-%s", spec.Name, consume.Name),
-				}
-				return
-			}
-		}
+	if link, found := crossDeploymentConsumer(consume, options); found {
+		consuming[consume.Name] = link
 	}
 }
 
+var (
+	credentialsName   = regexp.MustCompile(`(\A|[_.])credentials\z`)
+	secretName        = regexp.MustCompile(`(\A|[_.])(password|secret|token|key)\z`)
+	secretDescription = regexp.MustCompile(`(?i)\b(password|secret|token|key)\b`)
+)
+
 func DeterminePropertyBlueprintType(name string, property Property) (string, error) {
+	if property.rule != nil && property.rule.Type != "" {
+		return property.rule.Type, nil
+	}
+
 	if regexp.MustCompile(`[_.]port\z`).MatchString(name) {
 		return "port", nil
 	}
@@ -348,6 +630,22 @@ func DeterminePropertyBlueprintType(name string, property Property) (string, err
 		return "rsa_cert_credentials", nil
 	case "rsa", "ssh":
 		return "rsa_pkey_credentials", nil
+	case "password":
+		return "secret", nil
+	}
+
+	if property.Default == nil {
+		if credentialsName.MatchString(name) {
+			return "simple_credentials", nil
+		}
+
+		if secretName.MatchString(name) {
+			return "secret", nil
+		}
+
+		if property.Example == nil && secretDescription.MatchString(property.Description) {
+			return "secret", nil
+		}
 	}
 
 	var unknown interface{}
@@ -358,20 +656,33 @@ func DeterminePropertyBlueprintType(name string, property Property) (string, err
 		}
 	}
 
-	switch unknown.(type) {
-	case int, float32, float64:
-		return "integer", nil
+	if pbType, ok := valueType(unknown); ok {
+		return pbType, nil
+	}
+
+	return "", fmt.Errorf("not able to determine type for property %s: %#v", name, property)
+}
+
+// valueType is the property blueprint type of a default or example value.
+func valueType(value interface{}) (string, bool) {
+	if _, ok := listOfMaps(value); ok {
+		return "collection", true
+	}
+
+	switch value.(type) {
+	case int, int64, uint64, float32, float64:
+		return "integer", true
 	case nil, string:
-		return "string", nil
+		return "string", true
 	case bool:
-		return "boolean", nil
+		return "boolean", true
 	case []interface{}:
-		return "string_list", nil
+		return "string_list", true
 	case map[interface{}]interface{}:
-		return "collection", nil
+		return "collection", true
 	}
 
-	return "", fmt.Errorf("not able to determine type for property %s: %#v", name, property)
+	return "", false
 }
 
 func breakApartName(name string) string {
@@ -14,10 +14,20 @@ var _ = Describe("Generating the tile", func() {
 			release, err := generator.ParseRelease(dir)
 			Expect(err).NotTo(HaveOccurred())
 
-			tile, err := generator.Tile(release)
+			tile, err := generator.Tile(generator.Options{}, release)
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(tile.Description).To(Equal(""))
+			Expect(tile.Releases).To(Equal([]tile2.Release{
+				{
+					Name:    "my-release",
+					Version: "1.0.0",
+					File:    "my-release-1.0.0.tgz",
+				},
+			}))
+			Expect(tile.StemcellCriteria).To(Equal(tile2.StemcellCriteria{}))
+			Expect(tile.FormTypes).To(HaveLen(2))
+
 			ft := tile.FormTypes[0]
 			Expect(ft.Name).To(Equal("properties"))
 			Expect(ft.Label).To(Equal("Properties"))
@@ -28,6 +38,11 @@ var _ = Describe("Generating the tile", func() {
 					Label:       "No Namespace",
 					Description: `This property has no namespace (".") in it.`,
 				},
+				{
+					Reference:   ".properties.some_long__property",
+					Label:       "Some Long Property",
+					Description: "A property.",
+				},
 			}))
 
 			ft = tile.FormTypes[1]
@@ -47,23 +62,21 @@ var _ = Describe("Generating the tile", func() {
 				},
 			}))
 
-			ft = tile.FormTypes[2]
-			Expect(ft.Name).To(Equal("some_long"))
-			Expect(ft.Label).To(Equal("Some Long"))
-
 			pb := tile.PropertyBlueprints
 			Expect(pb[0].Name).To(Equal("no_namespace"))
 			Expect(pb[0].Configurable).To(BeTrue())
 			Expect(pb[0].Optional).To(BeTrue())
 			Expect(pb[0].Type).To(Equal("string"))
 
-			Expect(pb[1].Name).To(Equal("some__property"))
-			Expect(pb[1].Type).To(Equal("integer"))
-			Expect(pb[1].Default).To(Equal(1))
-			Expect(pb[1].Optional).To(BeFalse())
+			Expect(pb[1].Name).To(Equal("some_long__property"))
 
-			Expect(pb[2].Name).To(Equal("some__tls_property"))
-			Expect(pb[2].Type).To(Equal("rsa_cert_credentials"))
+			Expect(pb[2].Name).To(Equal("some__property"))
+			Expect(pb[2].Type).To(Equal("integer"))
+			Expect(pb[2].Default).To(Equal(1))
+			Expect(pb[2].Optional).To(BeFalse())
+
+			Expect(pb[3].Name).To(Equal("some__tls_property"))
+			Expect(pb[3].Type).To(Equal("rsa_cert_credentials"))
 
 			jobs := tile.JobTypes
 			Expect(jobs[0].Name).To(Equal("other"))
@@ -164,7 +177,7 @@ properties:
 				Specs: []generator.SpecPayload{spec},
 			}
 
-			tile, err := generator.Tile(release)
+			tile, err := generator.Tile(generator.Options{}, release)
 			Expect(err).NotTo(HaveOccurred())
 
 			pb := tile.PropertyBlueprints
@@ -180,4 +193,193 @@ properties:
 			Expect(pb[0].PropertyBlueprints[1].Optional).To(BeTrue())
 		})
 	})
+
+	When("there is a map default in the spec", func() {
+		const specWithMap = `
+name: example
+
+properties:
+  some.labels:
+    default:
+      env: dev
+      tags: {team: core}
+  other.labels:
+    example:
+      env: dev
+`
+		It("creates the same key and value collection as a map example", func() {
+			spec, err := generator.ParseSpec(writeFile(specWithMap))
+			Expect(err).NotTo(HaveOccurred())
+
+			tile, err := generator.Tile(generator.Options{}, generator.BoshReleasePayload{
+				Specs: []generator.SpecPayload{spec},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			blueprints := map[string]tile2.PropertyBlueprint{}
+			for _, pb := range tile.PropertyBlueprints {
+				blueprints[pb.Name] = pb
+			}
+
+			withDefault, withExample := blueprints["some__labels"], blueprints["other__labels"]
+			Expect(withDefault.Type).To(Equal("collection"))
+			Expect(withDefault.Type).To(Equal(withExample.Type))
+			Expect(withDefault.PropertyBlueprints).To(Equal(withExample.PropertyBlueprints))
+			Expect(withDefault.Default).To(Equal([]map[string]interface{}{
+				{"key": "env", "value": "dev"},
+				{"key": "tags", "value": "team: core"},
+			}))
+			Expect(withDefault.ValidateValue(withDefault.Default)).To(Succeed())
+		})
+	})
+
+	When("provided multiple releases", func() {
+		const providerSpec = `
+name: server
+provides:
+- name: database
+  type: database
+properties:
+  some.property:
+    default: 1
+`
+		const consumerSpec = `
+name: client
+consumes:
+- name: database
+  type: database
+properties:
+  some.property:
+    default: 2
+`
+		It("groups the properties per release and links jobs across releases", func() {
+			server, err := generator.ParseSpec(writeFile(providerSpec))
+			Expect(err).NotTo(HaveOccurred())
+
+			client, err := generator.ParseSpec(writeFile(consumerSpec))
+			Expect(err).NotTo(HaveOccurred())
+
+			tile, err := generator.Tile(
+				generator.Options{},
+				generator.BoshReleasePayload{
+					Name:          "my-database",
+					LatestVersion: "1.2.3",
+					File:          "my-database-1.2.3.tgz",
+					SHA1:          "abc123",
+					CompiledPackages: []generator.CompiledPackage{
+						{Name: "postgres", StemcellOS: "ubuntu-xenial", StemcellVersion: "621.74"},
+					},
+					Specs: []generator.SpecPayload{server},
+				},
+				generator.BoshReleasePayload{
+					Name:          "my-app",
+					LatestVersion: "4.5.6",
+					File:          "my-app-4.5.6.tgz",
+					SHA1:          "def456",
+					Specs:         []generator.SpecPayload{client},
+				},
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tile.Releases).To(Equal([]tile2.Release{
+				{Name: "my-database", Version: "1.2.3", File: "my-database-1.2.3.tgz", SHA1: "abc123"},
+				{Name: "my-app", Version: "4.5.6", File: "my-app-4.5.6.tgz", SHA1: "def456"},
+			}))
+			Expect(tile.StemcellCriteria).To(Equal(tile2.StemcellCriteria{OS: "ubuntu-xenial", Version: "621.74"}))
+
+			Expect(tile.FormTypes).To(HaveLen(2))
+			Expect(tile.FormTypes[0].Name).To(Equal("my_database_some"))
+			Expect(tile.FormTypes[0].Label).To(Equal("My Database Some"))
+			Expect(tile.FormTypes[0].PropertyInputs[0].Reference).To(Equal(".properties.my_database__some__property"))
+			Expect(tile.FormTypes[1].Name).To(Equal("my_app_some"))
+			Expect(tile.FormTypes[1].PropertyInputs[0].Reference).To(Equal(".properties.my_app__some__property"))
+
+			Expect(tile.PropertyBlueprints[0].Name).To(Equal("my_database__some__property"))
+			Expect(tile.PropertyBlueprints[0].Default).To(Equal(1))
+			Expect(tile.PropertyBlueprints[1].Name).To(Equal("my_app__some__property"))
+			Expect(tile.PropertyBlueprints[1].Default).To(Equal(2))
+
+			jobs := tile.JobTypes
+			Expect(jobs[0].Templates[0].Release).To(Equal("my-database"))
+			Expect(jobs[0].Manifest).To(MatchYAML("some: {property: ((.properties.my_database__some__property.value))}"))
+			Expect(jobs[1].Templates[0].Release).To(Equal("my-app"))
+			Expect(jobs[1].Templates[0].Consumes).To(MatchYAML("database: {from: server-database}"))
+			Expect(jobs[1].Manifest).To(MatchYAML("some: {property: ((.properties.my_app__some__property.value))}"))
+		})
+
+		It("errors when releases have jobs with the same name", func() {
+			server, err := generator.ParseSpec(writeFile(providerSpec))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = generator.Tile(
+				generator.Options{},
+				generator.BoshReleasePayload{Name: "my-database", Specs: []generator.SpecPayload{server}},
+				generator.BoshReleasePayload{Name: "other-database", Specs: []generator.SpecPayload{server}},
+			)
+			Expect(err).To(MatchError("job server is defined in both release my-database and release other-database"))
+		})
+	})
+
+	When("the property blueprints are job scoped", func() {
+		const webSpec = `
+name: web-server
+properties:
+  port:
+    default: 8080
+  tls.enabled:
+    default: false
+  tls.certificate:
+    description: The certificate
+`
+		const workerSpec = `
+name: worker
+properties:
+  port:
+    default: 9090
+`
+		It("creates the property blueprints and a form per job", func() {
+			web, err := generator.ParseSpec(writeFile(webSpec))
+			Expect(err).NotTo(HaveOccurred())
+
+			worker, err := generator.ParseSpec(writeFile(workerSpec))
+			Expect(err).NotTo(HaveOccurred())
+
+			tile, err := generator.Tile(
+				generator.Options{JobScoped: true},
+				generator.BoshReleasePayload{Name: "my-release", Specs: []generator.SpecPayload{web, worker}},
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tile.PropertyBlueprints).To(BeEmpty())
+
+			Expect(tile.FormTypes).To(HaveLen(2))
+			Expect(tile.FormTypes[0].Name).To(Equal("web_server"))
+			Expect(tile.FormTypes[0].PropertyInputs[0].Reference).To(Equal(".web-server.port"))
+			Expect(tile.FormTypes[0].PropertyInputs[1].Reference).To(Equal(".web-server.tls"))
+			Expect(tile.FormTypes[1].Name).To(Equal("worker"))
+			Expect(tile.FormTypes[1].PropertyInputs[0].Reference).To(Equal(".worker.port"))
+
+			web2, worker2 := tile.JobTypes[0], tile.JobTypes[1]
+			Expect(web2.PropertyBlueprints).To(HaveLen(2))
+			Expect(web2.PropertyBlueprints[0].Name).To(Equal("port"))
+			Expect(web2.PropertyBlueprints[0].Default).To(Equal(8080))
+			Expect(web2.PropertyBlueprints[1].Name).To(Equal("tls"))
+			Expect(web2.PropertyBlueprints[1].Type).To(Equal("selector"))
+			Expect(web2.Manifest).To(MatchYAML(`
+port: ((.web-server.port.value))
+tls: ((.web-server.tls.selected_option.parsed_manifest(manifest)))
+`))
+
+			Expect(worker2.PropertyBlueprints).To(HaveLen(1))
+			Expect(worker2.PropertyBlueprints[0].Default).To(Equal(9090))
+			Expect(worker2.Manifest).To(MatchYAML("port: ((.worker.port.value))"))
+
+			for _, ft := range tile.FormTypes {
+				for _, input := range ft.PropertyInputs {
+					_, found := tile.FindPropertyBlueprintFromPropertyInput(input.Reference)
+					Expect(found).To(BeTrue(), input.Reference)
+				}
+			}
+		})
+	})
 })
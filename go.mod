@@ -14,7 +14,6 @@ require (
 	github.com/go-playground/universal-translator v0.17.0
 	github.com/gobuffalo/packr/v2 v2.7.1
 	github.com/golang/snappy v0.0.1 // indirect
-	github.com/imdario/mergo v0.3.8
 	github.com/jessevdk/go-flags v1.4.0
 	github.com/labstack/echo v3.3.10+incompatible
 	github.com/labstack/gommon v0.3.0 // indirect
@@ -32,5 +31,6 @@ require (
 	gopkg.in/go-playground/assert.v1 v1.2.1 // indirect
 	gopkg.in/go-playground/validator.v9 v9.30.0
 	gopkg.in/yaml.v2 v2.3.0
+	gopkg.in/yaml.v3 v3.0.1
 	howett.net/ranger v0.0.0-20171016084633-e2e137620847
 )
@@ -8,15 +8,38 @@ import (
 )
 
 var command struct {
-	Generate     commands.Generate     `command:"generate"`
-	Preview      commands.Preview      `command:"preview"`
-	ValidateTile commands.ValidateTile `command:"validate-tile"`
+	Build                 commands.Build                 `command:"build"`
+	Generate              commands.Generate              `command:"generate"`
+	GenerateMigrations    commands.GenerateMigrations    `command:"generate-migrations"`
+	GenerateProductConfig commands.GenerateProductConfig `command:"generate-product-config"`
+	Preview               commands.Preview               `command:"preview"`
+	ValidateTile          commands.ValidateTile          `command:"validate-tile"`
+	ValidateProductConfig commands.ValidateProductConfig `command:"validate-product-config"`
 }
 
 func main() {
+	command.Build = commands.Build{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	command.Generate = commands.Generate{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	command.GenerateMigrations = commands.GenerateMigrations{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
+	command.GenerateProductConfig = commands.GenerateProductConfig{
+		Stdout: os.Stdout,
+	}
 	command.ValidateTile = commands.ValidateTile{
 		Stdout: os.Stdout,
 	}
+	command.ValidateProductConfig = commands.ValidateProductConfig{
+		Stdout: os.Stdout,
+		Stderr: os.Stderr,
+	}
 
 	_, err := flags.Parse(&command)
 	if flagsErr, ok := err.(*flags.Error); ok && flagsErr.Type == flags.ErrHelp {
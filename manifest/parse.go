@@ -29,9 +29,58 @@ type Features struct {
 	UseShortDNSAddresses bool `yaml:"use_short_dns_addresses"`
 }
 
+type Update struct {
+	Canaries        int         `yaml:",omitempty"`
+	MaxInFlight     interface{} `yaml:"max_in_flight,omitempty"`
+	CanaryWatchTime string      `yaml:"canary_watch_time,omitempty"`
+	UpdateWatchTime string      `yaml:"update_watch_time,omitempty"`
+	Serial          *bool       `yaml:",omitempty"`
+}
+
+type Job struct {
+	Name       string                 `validate:"required"`
+	Release    string                 `validate:"required"`
+	Consumes   map[string]interface{} `yaml:",omitempty"`
+	Provides   map[string]interface{} `yaml:",omitempty"`
+	Properties map[string]interface{} `yaml:",omitempty"`
+}
+
+type Network struct {
+	Name string `validate:"required"`
+}
+
+type VMResources struct {
+	CPU               int `yaml:"cpu"`
+	RAM               int `yaml:"ram"`
+	EphemeralDiskSize int `yaml:"ephemeral_disk_size"`
+}
+
+type InstanceGroup struct {
+	Name           string       `validate:"required"`
+	AZs            []string     `yaml:"azs" validate:"required"`
+	Instances      int          `validate:"gte=0"`
+	Jobs           []Job        `validate:"required,dive"`
+	VMResources    *VMResources `yaml:"vm_resources,omitempty"`
+	Stemcell       string       `validate:"required"`
+	PersistentDisk int          `yaml:"persistent_disk,omitempty"`
+	Networks       []Network    `validate:"required,dive"`
+	Update         *Update      `yaml:",omitempty"`
+	// Properties are shared by all the jobs of the instance group.
+	Properties map[string]interface{} `yaml:",omitempty"`
+}
+
+type Variable struct {
+	Name    string                 `validate:"required"`
+	Type    string                 `validate:"required"`
+	Options map[string]interface{} `yaml:",omitempty"`
+}
+
 type Payload struct {
-	Name      string     `validate:"required"`
-	Features  Features   `yaml:",omitempty"`
-	Releases  []Release  `validate:"required,dive"`
-	Stemcells []Stemcell `validate:"required,dive"`
+	Name           string          `validate:"required"`
+	Features       Features        `yaml:",omitempty"`
+	Releases       []Release       `validate:"required,dive"`
+	Stemcells      []Stemcell      `validate:"required,dive"`
+	Update         Update          `yaml:",omitempty"`
+	InstanceGroups []InstanceGroup `yaml:"instance_groups,omitempty" validate:"dive"`
+	Variables      []Variable      `yaml:",omitempty" validate:"dive"`
 }
@@ -0,0 +1,232 @@
+package merge
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// operation is an operation of a BOSH ops-file. Its path is made of map keys,
+// list indexes, `-` to append to a list, and `key=value` to find the element
+// of a list. A segment ending in `?` makes the rest of the path optional:
+// missing keys and elements are created by a replace, and skipped by a remove.
+type operation struct {
+	Type  string
+	Path  string
+	Value interface{} `yaml:",omitempty"`
+}
+
+var indexModifier = regexp.MustCompile(`:(prev|next|before|after)\z`)
+
+type segment struct {
+	key      string
+	index    int
+	isIndex  bool
+	isAppend bool
+	field    string
+	value    string
+	optional bool
+}
+
+// OpsFile applies the subset of BOSH ops-files (go-patch) operations that
+// edits a tile needs: replace and remove operations, with paths of keys,
+// indexes (negative ones count from the end), `-` and `key=value` segments,
+// and `?` optional segments. Other operations, the root path `/` and index
+// modifiers like `:prev` or `:after` are rejected rather than applied
+// differently than `bosh` would.
+func OpsFile(contents, ops []byte) ([]byte, error) {
+	var document interface{}
+
+	err := yaml.Unmarshal(contents, &document)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal tile: %s", err)
+	}
+
+	var operations []operation
+
+	err = yaml.UnmarshalStrict(ops, &operations)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal ops-file: %s", err)
+	}
+
+	for index, op := range operations {
+		if op.Type != "replace" && op.Type != "remove" {
+			return nil, fmt.Errorf("operation %d has an unknown type %q", index, op.Type)
+		}
+
+		segments, err := parsePath(op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d has an invalid path: %s", index, err)
+		}
+
+		document, err = op.apply(document, segments)
+		if err != nil {
+			return nil, fmt.Errorf("could not apply operation %d (%s %s): %s", index, op.Type, op.Path, err)
+		}
+	}
+
+	merged, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal merged tile: %s", err)
+	}
+
+	return merged, nil
+}
+
+func parsePath(path string) ([]segment, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("%q has to start with /", path)
+	}
+
+	if path == "/" {
+		return nil, fmt.Errorf("the root path / is not supported")
+	}
+
+	var (
+		segments []segment
+		optional bool
+	)
+
+	for _, part := range strings.Split(path[1:], "/") {
+		if strings.HasSuffix(part, "?") {
+			part = strings.TrimSuffix(part, "?")
+			optional = true
+		}
+
+		if modifier := indexModifier.FindString(part); modifier != "" {
+			return nil, fmt.Errorf("the index modifier %q of %q is not supported", modifier, part)
+		}
+
+		part = strings.Replace(strings.Replace(part, "~1", "/", -1), "~0", "~", -1)
+		s := segment{key: part, optional: optional}
+
+		if part == "-" {
+			s.isAppend = true
+		} else if index, err := strconv.Atoi(part); err == nil {
+			s.index = index
+			s.isIndex = true
+		} else if equals := strings.Index(part, "="); equals > 0 {
+			s.field, s.value = part[:equals], part[equals+1:]
+		}
+
+		segments = append(segments, s)
+	}
+
+	return segments, nil
+}
+
+func (o operation) apply(node interface{}, segments []segment) (interface{}, error) {
+	if len(segments) == 0 {
+		return o.Value, nil
+	}
+
+	s, last := segments[0], len(segments) == 1
+
+	if s.isAppend || s.isIndex || s.field != "" {
+		list, ok := node.([]interface{})
+		if !ok {
+			if node != nil || !s.optional {
+				return nil, fmt.Errorf("expected a list at %q", s.key)
+			}
+		}
+
+		return o.applyList(list, s, segments[1:], last)
+	}
+
+	mapping, ok := node.(map[interface{}]interface{})
+	if !ok {
+		if node != nil || !s.optional {
+			return nil, fmt.Errorf("expected a map at %q", s.key)
+		}
+
+		mapping = map[interface{}]interface{}{}
+	}
+
+	child, found := mapping[s.key]
+	if !found && !s.optional {
+		return nil, fmt.Errorf("missing key %q", s.key)
+	}
+
+	if o.Type == "remove" && (last || !found) {
+		delete(mapping, s.key)
+		return mapping, nil
+	}
+
+	child, err := o.apply(child, segments[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	mapping[s.key] = child
+
+	return mapping, nil
+}
+
+func (o operation) applyList(list []interface{}, s segment, rest []segment, last bool) (interface{}, error) {
+	if s.isAppend {
+		if o.Type == "remove" || !last {
+			return nil, fmt.Errorf("can only append a value to the end of a list")
+		}
+
+		return append(list, o.Value), nil
+	}
+
+	index := -1
+
+	if s.isIndex {
+		index = s.index
+		if index < 0 {
+			index += len(list)
+		}
+
+		if index < 0 || index >= len(list) {
+			if !s.optional {
+				return nil, fmt.Errorf("index %d is out of range", s.index)
+			}
+
+			index = -1
+		}
+	} else {
+		for i, element := range list {
+			if mapping, ok := element.(map[interface{}]interface{}); ok && fmt.Sprintf("%v", mapping[s.field]) == s.value {
+				index = i
+				break
+			}
+		}
+
+		if index < 0 && !s.optional {
+			return nil, fmt.Errorf("missing element %q", s.key)
+		}
+	}
+
+	if o.Type == "remove" && (last || index < 0) {
+		if index < 0 {
+			return list, nil
+		}
+
+		return append(list[:index:index], list[index+1:]...), nil
+	}
+
+	var element interface{}
+
+	if index < 0 {
+		if s.field != "" {
+			element = map[interface{}]interface{}{s.field: s.value}
+		}
+
+		list = append(list, element)
+		index = len(list) - 1
+	}
+
+	element, err := o.apply(list[index], rest)
+	if err != nil {
+		return nil, err
+	}
+
+	list[index] = element
+
+	return list, nil
+}
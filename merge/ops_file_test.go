@@ -0,0 +1,93 @@
+package merge_test
+
+import (
+	"github.com/jtarchie/tile-builder/merge"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OpsFile", func() {
+	const tile = `
+name: generated
+job_types:
+- name: web
+  resource_label: Web
+  templates:
+  - name: web
+    release: my-release
+- name: worker
+  resource_label: Worker
+`
+
+	It("replaces and removes values by path", func() {
+		contents, err := merge.OpsFile([]byte(tile), []byte(`
+- type: replace
+  path: /job_types/name=web/resource_label
+  value: Web Server
+- type: replace
+  path: /job_types/name=web/templates/-
+  value: {name: metrics, release: other-release}
+- type: replace
+  path: /job_types/0/instance_definition?/default
+  value: 3
+- type: replace
+  path: /job_types/name=errand?/resource_label
+  value: Errand
+- type: remove
+  path: /job_types/name=worker
+- type: remove
+  path: /icon_image?
+`))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(contents).To(MatchYAML(`
+name: generated
+job_types:
+- name: web
+  resource_label: Web Server
+  instance_definition:
+    default: 3
+  templates:
+  - name: web
+    release: my-release
+  - name: metrics
+    release: other-release
+- name: errand
+  resource_label: Errand
+`))
+	})
+
+	It("errors on a missing path", func() {
+		_, err := merge.OpsFile([]byte(tile), []byte(`[{type: replace, path: /job_types/name=errand/resource_label, value: Errand}]`))
+		Expect(err).To(MatchError(`could not apply operation 0 (replace /job_types/name=errand/resource_label): missing element "name=errand"`))
+
+		_, err = merge.OpsFile([]byte(tile), []byte(`[{type: remove, path: /label}]`))
+		Expect(err).To(MatchError(`could not apply operation 0 (remove /label): missing key "label"`))
+
+		_, err = merge.OpsFile([]byte(tile), []byte(`[{type: move, path: /label}]`))
+		Expect(err).To(MatchError(`operation 0 has an unknown type "move"`))
+
+		_, err = merge.OpsFile([]byte(tile), []byte(`[{type: remove, path: label}]`))
+		Expect(err).To(MatchError(`operation 0 has an invalid path: "label" has to start with /`))
+	})
+
+	It("replaces values by a negative index", func() {
+		contents, err := merge.OpsFile([]byte(tile), []byte(`[{type: replace, path: /job_types/-1/resource_label, value: Workers}]`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(contents).To(ContainSubstring("resource_label: Workers"))
+	})
+
+	It("errors on the paths bosh supports but it does not", func() {
+		_, err := merge.OpsFile([]byte(tile), []byte(`[{type: replace, path: /, value: {name: other}}]`))
+		Expect(err).To(MatchError(`operation 0 has an invalid path: the root path / is not supported`))
+
+		_, err = merge.OpsFile([]byte(tile), []byte(`[{type: replace, path: /job_types/0:after, value: {name: other}}]`))
+		Expect(err).To(MatchError(`operation 0 has an invalid path: the index modifier ":after" of "0:after" is not supported`))
+
+		_, err = merge.OpsFile([]byte(tile), []byte(`[{type: replace, path: /job_types/name=web:prev/resource_label, value: Web}]`))
+		Expect(err).To(MatchError(ContainSubstring(`the index modifier ":prev" of "name=web:prev" is not supported`)))
+
+		_, err = merge.OpsFile([]byte(tile), []byte(`[{type: test, path: /name, value: generated}]`))
+		Expect(err).To(MatchError(`operation 0 has an unknown type "test"`))
+	})
+})
@@ -0,0 +1,177 @@
+package merge
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Mode string
+
+const (
+	// Override replaces the values of the tile with the ones of the overlay.
+	Override Mode = "override"
+	// Fill only sets the values missing from, or empty in, the tile.
+	Fill Mode = "fill"
+)
+
+// deleteMarker removes the key or named element it is set on, e.g.
+// `job_types: [{name: web, _delete: true}]`.
+const deleteMarker = "_delete"
+
+// Overlay merges the overlay into the tile. Maps are merged deeply, lists of
+// named elements, like job types or property blueprints, are merged by their
+// name, and other lists are replaced. It defaults to the Override mode.
+func Overlay(contents, overlay []byte, mode Mode) ([]byte, error) {
+	switch mode {
+	case "":
+		mode = Override
+	case Override, Fill:
+	default:
+		return nil, fmt.Errorf("unknown merge mode %q", mode)
+	}
+
+	var base, over interface{}
+
+	err := yaml.Unmarshal(contents, &base)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal tile: %s", err)
+	}
+
+	err = yaml.Unmarshal(overlay, &over)
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal overlay: %s", err)
+	}
+
+	merged, err := yaml.Marshal(overlayValue(base, over, mode == Override))
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal merged tile: %s", err)
+	}
+
+	return merged, nil
+}
+
+func overlayValue(base, overlay interface{}, override bool) interface{} {
+	switch overlay := overlay.(type) {
+	case map[interface{}]interface{}:
+		if baseMap, ok := base.(map[interface{}]interface{}); ok {
+			return overlayMapping(baseMap, overlay, override)
+		}
+	case []interface{}:
+		if key, ok := listKey(overlay); ok {
+			if baseList, ok := base.([]interface{}); ok {
+				if baseKey, ok := listKey(baseList); len(baseList) == 0 || (ok && baseKey == key) {
+					return overlayList(key, baseList, overlay, override)
+				}
+			}
+		}
+	}
+
+	if !override && !empty(base) {
+		return base
+	}
+
+	return withoutDeletions(overlay)
+}
+
+// empty values, like the blank label of a generated tile, are filled in.
+func empty(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []interface{}:
+		return len(value) == 0
+	case map[interface{}]interface{}:
+		return len(value) == 0
+	}
+
+	return false
+}
+
+func overlayMapping(base, overlay map[interface{}]interface{}, override bool) map[interface{}]interface{} {
+	merged := map[interface{}]interface{}{}
+	for key, value := range base {
+		merged[key] = value
+	}
+
+	for key, value := range overlay {
+		if deleted(value) {
+			delete(merged, key)
+			continue
+		}
+
+		if existing, found := merged[key]; found {
+			merged[key] = overlayValue(existing, value, override)
+		} else {
+			merged[key] = withoutDeletions(value)
+		}
+	}
+
+	return merged
+}
+
+func overlayList(key string, base, overlay []interface{}, override bool) []interface{} {
+	overlayElements := elementsByKey(key, overlay)
+	baseElements := elementsByKey(key, base)
+
+	merged := []interface{}{}
+
+	for _, element := range base {
+		value, found := overlayElements[elementKey(key, element)]
+
+		switch {
+		case !found:
+			merged = append(merged, element)
+		case !deleted(value):
+			merged = append(merged, overlayValue(element, value, override))
+		}
+	}
+
+	for _, element := range overlay {
+		if _, found := baseElements[elementKey(key, element)]; !found && !deleted(element) {
+			merged = append(merged, withoutDeletions(element))
+		}
+	}
+
+	return merged
+}
+
+func deleted(value interface{}) bool {
+	mapping, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+
+	marker, _ := mapping[deleteMarker].(bool)
+
+	return marker
+}
+
+// withoutDeletions drops the deleted keys and elements of a value that is
+// not in the tile, as there is nothing to delete.
+func withoutDeletions(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		mapping := map[interface{}]interface{}{}
+		for key, child := range value {
+			if !deleted(child) {
+				mapping[key] = withoutDeletions(child)
+			}
+		}
+
+		return mapping
+	case []interface{}:
+		list := []interface{}{}
+		for _, child := range value {
+			if !deleted(child) {
+				list = append(list, withoutDeletions(child))
+			}
+		}
+
+		return list
+	}
+
+	return value
+}
@@ -0,0 +1,149 @@
+package merge_test
+
+import (
+	"github.com/jtarchie/tile-builder/merge"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Overlay", func() {
+	const tile = `
+name: generated
+label: Generated
+releases:
+- name: my-release
+  version: 1.0.0
+job_types:
+- name: web
+  resource_label: Web
+  max_in_flight: 1
+- name: worker
+  resource_label: Worker
+property_blueprints:
+- name: port
+  type: integer
+  default: 8080
+- name: timeout
+  type: integer
+post_deploy_errands:
+- name: smoke-tests
+`
+
+	It("merges lists of named elements by name, overriding values", func() {
+		contents, err := merge.Overlay([]byte(tile), []byte(`
+label: My Tile
+icon_image: some-image
+job_types:
+- name: web
+  resource_label: Web Server
+- name: errand
+  resource_label: Errand
+property_blueprints:
+- name: port
+  type: port
+post_deploy_errands:
+- name: other-tests
+`), merge.Override)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(contents).To(MatchYAML(`
+name: generated
+label: My Tile
+icon_image: some-image
+releases:
+- name: my-release
+  version: 1.0.0
+job_types:
+- name: web
+  resource_label: Web Server
+  max_in_flight: 1
+- name: worker
+  resource_label: Worker
+- name: errand
+  resource_label: Errand
+property_blueprints:
+- name: port
+  type: port
+  default: 8080
+- name: timeout
+  type: integer
+post_deploy_errands:
+- name: smoke-tests
+- name: other-tests
+`))
+	})
+
+	It("only fills in missing values", func() {
+		contents, err := merge.Overlay([]byte(tile), []byte(`
+label: My Tile
+icon_image: some-image
+job_types:
+- name: web
+  resource_label: Web Server
+  single_az_only: true
+`), merge.Fill)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(contents).To(MatchYAML(`
+name: generated
+label: Generated
+icon_image: some-image
+releases:
+- name: my-release
+  version: 1.0.0
+job_types:
+- name: web
+  resource_label: Web
+  max_in_flight: 1
+  single_az_only: true
+- name: worker
+  resource_label: Worker
+property_blueprints:
+- name: port
+  type: integer
+  default: 8080
+- name: timeout
+  type: integer
+post_deploy_errands:
+- name: smoke-tests
+`))
+	})
+
+	It("deletes the keys and named elements with a deletion marker", func() {
+		contents, err := merge.Overlay([]byte(tile), []byte(`
+label: {_delete: true}
+job_types:
+- name: worker
+  _delete: true
+- name: errand
+  _delete: true
+property_blueprints:
+- name: port
+  default: {_delete: true}
+`), merge.Override)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(contents).To(MatchYAML(`
+name: generated
+releases:
+- name: my-release
+  version: 1.0.0
+job_types:
+- name: web
+  resource_label: Web
+  max_in_flight: 1
+property_blueprints:
+- name: port
+  type: integer
+- name: timeout
+  type: integer
+post_deploy_errands:
+- name: smoke-tests
+`))
+	})
+
+	It("errors on an unknown mode", func() {
+		_, err := merge.Overlay([]byte(tile), []byte(`{}`), merge.Mode("replace"))
+		Expect(err).To(MatchError(`unknown merge mode "replace"`))
+	})
+})
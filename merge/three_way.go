@@ -0,0 +1,269 @@
+package merge
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/jtarchie/tile-builder/metadata"
+	"gopkg.in/yaml.v2"
+)
+
+// Report describes what changed between the previous and the current
+// generated tile, and where the release changed a value that was also edited.
+type Report struct {
+	AddedProperties   []string
+	RemovedProperties []string
+	Conflicts         []string
+}
+
+// ThreeWay carries the edits made to the previous generated tile forward to
+// the current generated one. Lists of named elements, like job types and
+// property blueprints, are merged by their name, or by their reference for
+// property inputs. When both the edit and the release change a value, the
+// edit is kept, unless the release removed it.
+func ThreeWay(previous, edited, current []byte) ([]byte, Report, error) {
+	var base, ours, theirs interface{}
+
+	for _, document := range []struct {
+		name     string
+		contents []byte
+		value    *interface{}
+	}{
+		{"previous", previous, &base},
+		{"edited", edited, &ours},
+		{"current", current, &theirs},
+	} {
+		err := yaml.Unmarshal(document.contents, document.value)
+		if err != nil {
+			return nil, Report{}, fmt.Errorf("could not unmarshal %s tile: %s", document.name, err)
+		}
+	}
+
+	report, err := propertyChanges(previous, current)
+	if err != nil {
+		return nil, Report{}, err
+	}
+
+	m := &merger{}
+	merged := m.value("", base, true, ours, theirs)
+	report.Conflicts = m.conflicts
+	sort.Strings(report.Conflicts)
+
+	contents, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, Report{}, fmt.Errorf("could not marshal merged tile: %s", err)
+	}
+
+	return contents, report, nil
+}
+
+type merger struct {
+	conflicts []string
+}
+
+func (m *merger) value(path string, base interface{}, hasBase bool, ours, theirs interface{}) interface{} {
+	if hasBase && reflect.DeepEqual(ours, base) {
+		return theirs
+	}
+
+	if (hasBase && reflect.DeepEqual(theirs, base)) || reflect.DeepEqual(ours, theirs) {
+		return ours
+	}
+
+	if oursMap, ok := ours.(map[interface{}]interface{}); ok {
+		if theirsMap, ok := theirs.(map[interface{}]interface{}); ok {
+			baseMap, _ := base.(map[interface{}]interface{})
+			return m.mapping(path, baseMap, oursMap, theirsMap)
+		}
+	}
+
+	if oursKey, ok := listKey(ours); ok {
+		if theirsKey, ok := listKey(theirs); ok && oursKey == theirsKey {
+			baseList, _ := base.([]interface{})
+			return m.list(path, oursKey, baseList, ours.([]interface{}), theirs.([]interface{}))
+		}
+	}
+
+	m.conflicts = append(m.conflicts, path)
+
+	return ours
+}
+
+func (m *merger) mapping(path string, base, ours, theirs map[interface{}]interface{}) map[interface{}]interface{} {
+	merged := map[interface{}]interface{}{}
+
+	for key, value := range ours {
+		if _, found := theirs[key]; found {
+			continue
+		}
+
+		// otherwise it was removed by the release
+		if _, found := base[key]; !found {
+			merged[key] = value
+		}
+	}
+
+	for key, theirsValue := range theirs {
+		keyPath := fmt.Sprintf("%s.%v", path, key)
+		if path == "" {
+			keyPath = fmt.Sprintf("%v", key)
+		}
+
+		baseValue, inBase := base[key]
+
+		if oursValue, found := ours[key]; found {
+			merged[key] = m.value(keyPath, baseValue, inBase, oursValue, theirsValue)
+			continue
+		}
+
+		if !inBase {
+			merged[key] = theirsValue
+		} else if !reflect.DeepEqual(baseValue, theirsValue) {
+			// removed by the edit, but changed by the release
+			m.conflicts = append(m.conflicts, keyPath)
+		}
+	}
+
+	return merged
+}
+
+// list merges the elements by their key. The edited order is kept, followed
+// by the elements the release added.
+func (m *merger) list(path, key string, base, ours, theirs []interface{}) []interface{} {
+	baseElements := elementsByKey(key, base)
+	theirsElements := elementsByKey(key, theirs)
+	oursElements := elementsByKey(key, ours)
+
+	var merged []interface{}
+
+	for _, element := range ours {
+		name := elementKey(key, element)
+		elementPath := fmt.Sprintf("%s[%s]", path, name)
+
+		baseElement, inBase := baseElements[name]
+
+		if theirsElement, found := theirsElements[name]; found {
+			merged = append(merged, m.value(elementPath, baseElement, inBase, element, theirsElement))
+		} else if !inBase {
+			merged = append(merged, element)
+		}
+	}
+
+	for _, element := range theirs {
+		name := elementKey(key, element)
+		if _, found := oursElements[name]; found {
+			continue
+		}
+
+		if baseElement, inBase := baseElements[name]; !inBase {
+			merged = append(merged, element)
+		} else if !reflect.DeepEqual(baseElement, element) {
+			m.conflicts = append(m.conflicts, fmt.Sprintf("%s[%s]", path, name))
+		}
+	}
+
+	return merged
+}
+
+// listKey is the field identifying the elements of the list, when each
+// of them has one.
+func listKey(value interface{}) (string, bool) {
+	list, ok := value.([]interface{})
+	if !ok || len(list) == 0 {
+		return "", false
+	}
+
+	for _, key := range []string{"name", "reference"} {
+		keyed := true
+
+		for _, element := range list {
+			if elementKey(key, element) == "" {
+				keyed = false
+				break
+			}
+		}
+
+		if keyed {
+			return key, true
+		}
+	}
+
+	return "", false
+}
+
+func elementKey(key string, element interface{}) string {
+	mapping, ok := element.(map[interface{}]interface{})
+	if !ok {
+		return ""
+	}
+
+	name, _ := mapping[key].(string)
+
+	return name
+}
+
+func elementsByKey(key string, list []interface{}) map[string]interface{} {
+	elements := map[string]interface{}{}
+	for _, element := range list {
+		if name := elementKey(key, element); name != "" {
+			elements[name] = element
+		}
+	}
+
+	return elements
+}
+
+// propertyChanges reports the property blueprints, by their reference, that
+// were added to or removed from the release.
+func propertyChanges(previous, current []byte) (Report, error) {
+	var before, after metadata.Payload
+
+	err := yaml.Unmarshal(previous, &before)
+	if err != nil {
+		return Report{}, fmt.Errorf("could not unmarshal previous tile: %s", err)
+	}
+
+	err = yaml.Unmarshal(current, &after)
+	if err != nil {
+		return Report{}, fmt.Errorf("could not unmarshal current tile: %s", err)
+	}
+
+	beforeReferences := propertyReferences(before)
+	afterReferences := propertyReferences(after)
+
+	var report Report
+
+	for reference := range afterReferences {
+		if !beforeReferences[reference] {
+			report.AddedProperties = append(report.AddedProperties, reference)
+		}
+	}
+
+	for reference := range beforeReferences {
+		if !afterReferences[reference] {
+			report.RemovedProperties = append(report.RemovedProperties, reference)
+		}
+	}
+
+	sort.Strings(report.AddedProperties)
+	sort.Strings(report.RemovedProperties)
+
+	return report, nil
+}
+
+func propertyReferences(payload metadata.Payload) map[string]bool {
+	references := map[string]bool{}
+
+	for _, pb := range payload.PropertyBlueprints {
+		references[fmt.Sprintf(".properties.%s", pb.Name)] = true
+	}
+
+	for _, jobType := range payload.JobTypes {
+		for _, pb := range jobType.PropertyBlueprints {
+			references[fmt.Sprintf(".%s.%s", jobType.Name, pb.Name)] = true
+		}
+	}
+
+	return references
+}
@@ -0,0 +1,148 @@
+package merge_test
+
+import (
+	"github.com/jtarchie/tile-builder/merge"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ThreeWay", func() {
+	const previous = `
+name: example
+form_types:
+- name: web
+  label: Web
+  property_inputs:
+  - reference: .properties.port
+    label: Port
+  - reference: .properties.timeout
+    label: Timeout
+property_blueprints:
+- name: port
+  type: integer
+  default: 8080
+- name: timeout
+  type: integer
+  default: 30
+job_types:
+- name: web
+  resource_label: Web
+  resource_definitions:
+  - name: ram
+    default: 8192
+  - name: cpu
+    default: 1
+`
+	const edited = `
+name: example
+icon_image: some-image
+form_types:
+- name: web
+  label: Web Server
+  property_inputs:
+  - reference: .properties.timeout
+    label: Request Timeout
+  - reference: .properties.port
+    label: Port
+property_blueprints:
+- name: port
+  type: port
+  default: 8080
+- name: timeout
+  type: integer
+  default: 60
+job_types:
+- name: web
+  resource_label: Web Server
+  resource_definitions:
+  - name: ram
+    default: 16384
+  - name: cpu
+    default: 1
+`
+	const current = `
+name: example
+form_types:
+- name: web
+  label: Web
+  property_inputs:
+  - reference: .properties.port
+    label: Port
+  - reference: .properties.log_level
+    label: Log Level
+property_blueprints:
+- name: port
+  type: integer
+  default: 9090
+- name: log_level
+  type: string
+  default: info
+job_types:
+- name: web
+  resource_label: Web
+  resource_definitions:
+  - name: ram
+    default: 8192
+  - name: cpu
+    default: 2
+`
+
+	It("carries the edits forward to the regenerated tile", func() {
+		contents, _, err := merge.ThreeWay([]byte(previous), []byte(edited), []byte(current))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(contents).To(MatchYAML(`
+name: example
+icon_image: some-image
+form_types:
+- name: web
+  label: Web Server
+  property_inputs:
+  - reference: .properties.port
+    label: Port
+  - reference: .properties.log_level
+    label: Log Level
+property_blueprints:
+- name: port
+  type: port
+  default: 9090
+- name: log_level
+  type: string
+  default: info
+job_types:
+- name: web
+  resource_label: Web Server
+  resource_definitions:
+  - name: ram
+    default: 16384
+  - name: cpu
+    default: 2
+`))
+	})
+
+	It("reports the added and removed properties", func() {
+		_, report, err := merge.ThreeWay([]byte(previous), []byte(edited), []byte(current))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(report.AddedProperties).To(Equal([]string{".properties.log_level"}))
+		Expect(report.RemovedProperties).To(Equal([]string{".properties.timeout"}))
+		Expect(report.Conflicts).To(BeEmpty())
+	})
+
+	It("keeps the edit when the release changed the same value", func() {
+		contents, report, err := merge.ThreeWay(
+			[]byte(`{property_blueprints: [{name: port, default: 8080}]}`),
+			[]byte(`{property_blueprints: [{name: port, default: 8443}]}`),
+			[]byte(`{property_blueprints: [{name: port, default: 9090}]}`),
+		)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(contents).To(MatchYAML(`{property_blueprints: [{name: port, default: 8443}]}`))
+		Expect(report.Conflicts).To(Equal([]string{"property_blueprints[port].default"}))
+	})
+
+	It("errors on an invalid tile", func() {
+		_, _, err := merge.ThreeWay([]byte(`{}`), []byte(`{`), []byte(`{}`))
+		Expect(err).To(MatchError(ContainSubstring("could not unmarshal edited tile")))
+	})
+})
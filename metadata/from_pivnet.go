@@ -12,7 +12,6 @@ import (
 
 	"github.com/pivotal-cf/go-pivnet/v2"
 	"github.com/pivotal-cf/go-pivnet/v2/logshim"
-	"gopkg.in/yaml.v2"
 	"howett.net/ranger"
 )
 
@@ -56,43 +55,57 @@ func (h httpClient) Head(endpoint string) (*http.Response, error) {
 var _ ranger.HTTPClient = httpClient{}
 
 func FromPivnet(token, slug, version string, strict bool) (Payload, error) {
+	source, err := ReadPivnet(token, slug, version)
+	if err != nil {
+		return Payload{}, err
+	}
+
+	payload, err := Parse(source.Contents, strict)
+	if err != nil {
+		return payload, fmt.Errorf("could not unmarshal %s: %s", source.Filename, err)
+	}
+
+	return payload, nil
+}
+
+func ReadPivnet(token, slug, version string) (Source, error) {
 	var (
-		payload Payload
-		client  pivnet.Client
+		source Source
+		client pivnet.Client
 	)
 
 	client = createPivnetClient(token)
 
 	releases, err := client.Releases.List(slug)
 	if err != nil {
-		return payload, fmt.Errorf("could not get releases for product with %s: %s", slug, err)
+		return source, fmt.Errorf("could not get releases for product with %s: %s", slug, err)
 	}
 
 	for _, release := range releases {
 		if release.Version == version {
 			err := client.EULA.Accept(slug, release.ID)
 			if err != nil {
-				return payload, fmt.Errorf("could not match EULA for release %d: %s", release.ID, err)
+				return source, fmt.Errorf("could not match EULA for release %d: %s", release.ID, err)
 			}
 
 			productFiles, err := client.ProductFiles.ListForRelease(slug, release.ID)
 			if err != nil {
-				return payload, fmt.Errorf("could not get productFiles for release %d: %s", release.ID, err)
+				return source, fmt.Errorf("could not get productFiles for release %d: %s", release.ID, err)
 			}
 
 			for _, productFile := range productFiles {
 				matched, err := filepath.Match("*.pivotal", filepath.Base(productFile.AWSObjectKey))
 				if err != nil {
-					return payload, fmt.Errorf("could not match productFile %s: %s", productFile.AWSObjectKey, err)
+					return source, fmt.Errorf("could not match productFile %s: %s", productFile.AWSObjectKey, err)
 				}
 				if matched {
-					return downloadAndParseMetadata(productFile, payload, client, strict)
+					return downloadMetadata(productFile, client)
 				}
 			}
 		}
 	}
 
-	return payload, fmt.Errorf("could not find release with version %s for %s", version, slug)
+	return source, fmt.Errorf("could not find release with version %s for %s", version, slug)
 }
 
 func createPivnetClient(token string) pivnet.Client {
@@ -116,10 +129,10 @@ func createPivnetClient(token string) pivnet.Client {
 	)
 }
 
-func downloadAndParseMetadata(productFile pivnet.ProductFile, payload Payload, client pivnet.Client, strict bool) (Payload, error) {
+func downloadMetadata(productFile pivnet.ProductFile, client pivnet.Client) (Source, error) {
 	link, err := productFile.DownloadLink()
 	if err != nil {
-		return payload, fmt.Errorf("could not get download link for productFile: %s", err)
+		return Source{}, fmt.Errorf("could not get download link for productFile: %s", err)
 	}
 
 	parsedURL, _ := url.Parse(link)
@@ -132,41 +145,36 @@ func downloadAndParseMetadata(productFile pivnet.ProductFile, payload Payload, c
 
 	reader, err := ranger.NewReader(httpClient)
 	if err != nil {
-		return payload, fmt.Errorf("can not create a range client: %s", err)
+		return Source{}, fmt.Errorf("can not create a range client: %s", err)
 	}
 
 	length, err := reader.Length()
 	if err != nil {
-		return payload, fmt.Errorf("can not find length of productFile: %s", err)
+		return Source{}, fmt.Errorf("can not find length of productFile: %s", err)
 	}
 
 	zipReader, err := zip.NewReader(reader, length)
 	if err != nil {
-		return payload, fmt.Errorf("can not create a zip client: %s", err)
+		return Source{}, fmt.Errorf("can not create a zip client: %s", err)
 	}
 
 	for _, zipFile := range zipReader.File {
 		if metadataFile.MatchString(zipFile.Name) {
 			reader, err := zipFile.Open()
 			if err != nil {
-				return payload, fmt.Errorf("can not open zip file %s: %s", zipFile.Name, err)
+				return Source{}, fmt.Errorf("can not open zip file %s: %s", zipFile.Name, err)
 			}
 			contents, err := ioutil.ReadAll(reader)
 			if err != nil {
-				return payload, fmt.Errorf("can not read zip file %s: %s", zipFile.Name, err)
-			}
-
-			if strict {
-				err = yaml.UnmarshalStrict(contents, &payload)
-			} else {
-				err = yaml.Unmarshal(contents, &payload)
-			}
-			if err != nil {
-				return payload, fmt.Errorf("could not unmarshal %s: %s", zipFile.Name, err)
+				return Source{}, fmt.Errorf("can not read zip file %s: %s", zipFile.Name, err)
 			}
 
-			return payload, nil
+			return Source{
+				Filename: zipFile.Name,
+				Contents: contents,
+			}, nil
 		}
 	}
-	return Payload{}, nil
+
+	return Source{}, fmt.Errorf("could not find metadata file in %s", productFile.AWSObjectKey)
 }
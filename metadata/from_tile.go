@@ -7,13 +7,31 @@ import (
 	"io"
 
 	"github.com/mholt/archiver"
-	"gopkg.in/yaml.v2"
 )
 
+type Source struct {
+	Filename string
+	Contents []byte
+}
+
 func FromTile(tilePath string, strict bool) (Payload, error) {
+	source, err := ReadTile(tilePath)
+	if err != nil {
+		return Payload{}, err
+	}
+
+	payload, err := Parse(source.Contents, strict)
+	if err != nil {
+		return payload, fmt.Errorf("could not unmarshal %s: %s", tilePath, err)
+	}
+
+	return payload, nil
+}
+
+func ReadTile(tilePath string) (Source, error) {
 	var (
 		contents bytes.Buffer
-		payload  Payload
+		source   Source
 	)
 
 	archive := archiver.NewZip()
@@ -21,6 +39,7 @@ func FromTile(tilePath string, strict bool) (Payload, error) {
 		zfh, ok := f.Header.(zip.FileHeader)
 		if ok {
 			if metadataFile.MatchString(zfh.Name) {
+				source.Filename = zfh.Name
 				_, err := io.Copy(&contents, f)
 				return err
 			}
@@ -30,17 +49,10 @@ func FromTile(tilePath string, strict bool) (Payload, error) {
 	})
 
 	if err != nil {
-		return payload, fmt.Errorf("could not find metadata file in %s: %s", tilePath, err)
+		return source, fmt.Errorf("could not find metadata file in %s: %s", tilePath, err)
 	}
 
-	if strict {
-		err = yaml.UnmarshalStrict(contents.Bytes(), &payload)
-	} else {
-		err = yaml.Unmarshal(contents.Bytes(), &payload)
-	}
-	if err != nil {
-		return payload, fmt.Errorf("could not unmarshal %s: %s", tilePath, err)
-	}
+	source.Contents = contents.Bytes()
 
-	return payload, nil
-}
\ No newline at end of file
+	return source, nil
+}
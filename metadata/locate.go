@@ -0,0 +1,167 @@
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var (
+	fieldSegment = regexp.MustCompile(`\A(\w+)((?:\[[^\]]*\])*)\z`)
+	fieldIndex   = regexp.MustCompile(`\[([^\]]*)\]`)
+)
+
+type ValidationError struct {
+	Field   string
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// ValidateWithSource validates the payload and maps every validation back to
+// its location in the metadata file the payload was parsed from.
+func (p Payload) ValidateWithSource(contents []byte) ([]ValidationError, error) {
+	validations, err := p.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	var document yamlv3.Node
+
+	err = yamlv3.Unmarshal(contents, &document)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse metadata: %s", err)
+	}
+
+	var root *yamlv3.Node
+	if len(document.Content) > 0 {
+		root = document.Content[0]
+	}
+
+	validationErrors := []ValidationError{}
+	for field, message := range validations {
+		path, line, column := locate(root, field)
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   field,
+			Path:    path,
+			Line:    line,
+			Column:  column,
+			Message: message,
+		})
+	}
+
+	sort.Slice(validationErrors, func(i, j int) bool {
+		if validationErrors[i].Line != validationErrors[j].Line {
+			return validationErrors[i].Line < validationErrors[j].Line
+		}
+
+		if validationErrors[i].Column != validationErrors[j].Column {
+			return validationErrors[i].Column < validationErrors[j].Column
+		}
+
+		return validationErrors[i].Path < validationErrors[j].Path
+	})
+
+	return validationErrors, nil
+}
+
+// locate converts the struct namespace of a validation (`Payload.JobTypes[0].Name`)
+// to its YAML path, and the closest line and column in the document.
+func locate(root *yamlv3.Node, field string) (string, int, int) {
+	var (
+		path         string
+		line, column = 1, 1
+		node         = root
+		t            = reflect.TypeOf(Payload{})
+	)
+
+	if root != nil {
+		line, column = root.Line, root.Column
+	}
+
+	parts := strings.Split(field, ".")
+	for _, part := range parts[1:] {
+		match := fieldSegment.FindStringSubmatch(part)
+		if match == nil {
+			break
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		if t.Kind() != reflect.Struct {
+			break
+		}
+
+		structField, found := t.FieldByName(match[1])
+		if !found {
+			break
+		}
+
+		key := yamlName(structField)
+		path = joinPath(path, key)
+		t = structField.Type
+		node = mappingValue(node, key, &line, &column)
+
+		for _, index := range fieldIndex.FindAllStringSubmatch(match[2], -1) {
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				i, _ := strconv.Atoi(index[1])
+
+				var item *yamlv3.Node
+				if node != nil && node.Kind == yamlv3.SequenceNode && i < len(node.Content) {
+					item = node.Content[i]
+					line, column = item.Line, item.Column
+				}
+
+				path = elementPath(path, i, item)
+				node = item
+			case reflect.Map:
+				path = joinPath(path, index[1])
+				node = mappingValue(node, index[1], &line, &column)
+			default:
+				return path, line, column
+			}
+
+			t = t.Elem()
+		}
+	}
+
+	return path, line, column
+}
+
+func mappingValue(node *yamlv3.Node, key string, line, column *int) *yamlv3.Node {
+	if node != nil && node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+
+	if node == nil || node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			*line, *column = node.Content[i].Line, node.Content[i].Column
+
+			value := node.Content[i+1]
+			if value.Kind == yamlv3.AliasNode {
+				value = value.Alias
+			}
+
+			return value
+		}
+	}
+
+	return nil
+}
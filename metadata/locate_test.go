@@ -0,0 +1,62 @@
+package metadata_test
+
+import (
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Locating validations in the metadata file", func() {
+	It("returns the YAML path, line and column of each validation", func() {
+		contents := []byte(`---
+name: example
+icon_image: image
+product_version: 1.0.0
+minimum_version_for_upgrade: 0.0.0
+stemcell_criteria:
+  os: ubuntu-xenial
+releases:
+- name: release
+  file: release.tgz
+  version: 1.0.0
+job_types:
+- name: router
+  resource_label: Router
+  max_in_flight: 1
+  resource_definitions: [{name: cpu, label: CPU, type: integer}]
+  templates:
+  - name: router
+    release:
+property_blueprints:
+- type: string
+`)
+		payload, err := metadata.Parse(contents, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		validations, err := payload.ValidateWithSource(contents)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(validations).To(Equal([]metadata.ValidationError{
+			{
+				Field:   "Payload.StemcellCriteria.Version",
+				Path:    "stemcell_criteria.version",
+				Line:    6,
+				Column:  1,
+				Message: "Version is a required field",
+			},
+			{
+				Field:   "Payload.JobTypes[0].Templates[0].Release",
+				Path:    "job_types[name=router].templates[name=router].release",
+				Line:    19,
+				Column:  5,
+				Message: "Release is a required field",
+			},
+			{
+				Field:   "Payload.PropertyBlueprints[0].Name",
+				Path:    "property_blueprints[0].name",
+				Line:    21,
+				Column:  3,
+				Message: "Name is a required field",
+			},
+		}))
+	})
+})
@@ -58,10 +58,6 @@ type PropertyBlueprint struct {
 	Type               string              `validate:"required,oneof=boolean ca_certificate collection disk_type_dropdown domain dropdown_select email http_url integer ip_address ip_ranges ldap_url multi_select_options network_address network_address_list port rsa_cert_credentials rsa_pkey_credentials salted_credentials secret selector service_network_az_multi_select service_network_az_single_select simple_credentials smtp_authentication stemcell_selector string_list string text uuid vm_type_dropdown wildcard_domain"`
 }
 
-func (pb PropertyBlueprint) ValidateValue(value interface{}) error {
-	return nil
-}
-
 type Template struct {
 	Consumes string `yaml:",omitempty"`
 	Name     string `validate:"required"`
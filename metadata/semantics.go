@@ -0,0 +1,106 @@
+package metadata
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/go-playground/validator.v9"
+)
+
+// versionConstraint is one or more space separated constraints, which all
+// have to be met, like `>= 1.0 < 2.0`.
+var versionConstraint = regexp.MustCompile(`\A\s*` + singleConstraint + `(\s+` + singleConstraint + `)*\s*\z`)
+
+const singleConstraint = `(~>|>=|<=|!=|=|>|<|\^|~)?\s*v?\d+(\.(\d+|x|\*)){0,2}(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?`
+
+// semanticValidations checks the references between parts of the payload,
+// which cannot be expressed with struct tags.
+func (p Payload) semanticValidations() validator.ValidationErrorsTranslations {
+	validations := validator.ValidationErrorsTranslations{}
+
+	releases := map[string]bool{}
+	for _, release := range p.Releases {
+		releases[release.Name] = true
+	}
+
+	stemcells := map[string]bool{p.StemcellCriteria.OS: true}
+	for _, stemcell := range p.AdditionalStemcellsCriteria {
+		stemcells[stemcell.OS] = true
+	}
+
+	jobTypes := map[string]bool{}
+	for index, jobType := range p.JobTypes {
+		field := fmt.Sprintf("Payload.JobTypes[%d]", index)
+
+		if jobType.Name != "" && jobTypes[jobType.Name] {
+			validations[field+".Name"] = fmt.Sprintf("Name ('%s') is already used by another job type", jobType.Name)
+		}
+		jobTypes[jobType.Name] = true
+
+		for templateIndex, template := range jobType.Templates {
+			if template.Release != "" && !releases[template.Release] {
+				validations[fmt.Sprintf("%s.Templates[%d].Release", field, templateIndex)] = fmt.Sprintf("References a release ('%s') that does not exist", template.Release)
+			}
+		}
+
+		if jobType.UseStemcell != "" && !stemcells[jobType.UseStemcell] {
+			validations[field+".UseStemcell"] = fmt.Sprintf("References a stemcell OS ('%s') that is not in the stemcell criteria", jobType.UseStemcell)
+		}
+
+		reference := jobType.InstanceDefinition.ZeroIf.PropertyReference
+		if reference != "" {
+			if _, found := p.FindPropertyBlueprintFromPropertyInput(reference); !found {
+				validations[field+".InstanceDefinition.ZeroIf.PropertyReference"] = fmt.Sprintf("References a property blueprint ('%s') that does not exist", reference)
+			}
+		}
+
+		duplicatePropertyBlueprints(field, jobType.PropertyBlueprints, validations)
+	}
+
+	duplicatePropertyBlueprints("Payload", p.PropertyBlueprints, validations)
+
+	for name, errands := range map[string][]Errand{
+		"PostDeployErrands": p.PostDeployErrands,
+		"PreDeleteErrands":  p.PreDeleteErrands,
+	} {
+		for errandIndex, errand := range errands {
+			for instanceIndex, instance := range errand.Instances {
+				jobName := strings.Split(instance, "/")[0]
+				if !jobTypes[jobName] {
+					validations[fmt.Sprintf("Payload.%s[%d].Instances[%d]", name, errandIndex, instanceIndex)] = fmt.Sprintf("References a job type ('%s') that does not exist", jobName)
+				}
+			}
+		}
+	}
+
+	for index, productVersion := range p.RequiresProductVersions {
+		if productVersion.Version != "" && !validVersionConstraint(productVersion.Version) {
+			validations[fmt.Sprintf("Payload.RequiresProductVersions[%d].Version", index)] = fmt.Sprintf("Version ('%s') is not a valid version constraint", productVersion.Version)
+		}
+	}
+
+	return validations
+}
+
+func duplicatePropertyBlueprints(field string, blueprints []PropertyBlueprint, validations validator.ValidationErrorsTranslations) {
+	names := map[string]bool{}
+	for index, pb := range blueprints {
+		if pb.Name != "" && names[pb.Name] {
+			validations[fmt.Sprintf("%s.PropertyBlueprints[%d].Name", field, index)] = fmt.Sprintf("Name ('%s') is already used by another property blueprint", pb.Name)
+		}
+		names[pb.Name] = true
+	}
+}
+
+func validVersionConstraint(constraint string) bool {
+	for _, alternative := range strings.Split(constraint, "||") {
+		for _, part := range strings.Split(alternative, ",") {
+			if !versionConstraint.MatchString(part) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,194 @@
+package metadata
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+type UnknownKey struct {
+	Path   string
+	Line   int
+	Column int
+}
+
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+func (u UnknownKeysError) Error() string {
+	lines := []string{"found unknown keys in metadata:"}
+	for _, key := range u.Keys {
+		lines = append(lines, fmt.Sprintf("  line %d, column %d: %s", key.Line, key.Column, key.Path))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ValidationErrors reports the unknown keys like the other validations of a
+// tile.
+func (u UnknownKeysError) ValidationErrors() []ValidationError {
+	var validations []ValidationError
+	for _, key := range u.Keys {
+		validations = append(validations, ValidationError{
+			Path:    key.Path,
+			Line:    key.Line,
+			Column:  key.Column,
+			Message: "is not a known key",
+		})
+	}
+
+	return validations
+}
+
+// Parse unmarshals the contents of a metadata file. When strict, every key
+// that does not map to a field of the Payload is reported with its location.
+func Parse(contents []byte, strict bool) (Payload, error) {
+	var payload Payload
+
+	if !strict {
+		err := yaml.Unmarshal(contents, &payload)
+		return payload, err
+	}
+
+	keys, err := UnknownKeys(contents)
+	if err != nil {
+		return payload, err
+	}
+
+	if len(keys) > 0 {
+		return payload, UnknownKeysError{Keys: keys}
+	}
+
+	err = yaml.UnmarshalStrict(contents, &payload)
+	return payload, err
+}
+
+func UnknownKeys(contents []byte) ([]UnknownKey, error) {
+	var document yamlv3.Node
+
+	err := yamlv3.Unmarshal(contents, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+
+	var keys []UnknownKey
+	findUnknownKeys(document.Content[0], reflect.TypeOf(Payload{}), "", &keys)
+
+	return keys, nil
+}
+
+func findUnknownKeys(node *yamlv3.Node, t reflect.Type, path string, keys *[]UnknownKey) {
+	if node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yamlv3.MappingNode {
+			return
+		}
+
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				continue
+			}
+
+			keyPath := joinPath(path, key.Value)
+
+			field, found := fields[key.Value]
+			if !found {
+				*keys = append(*keys, UnknownKey{
+					Path:   keyPath,
+					Line:   key.Line,
+					Column: key.Column,
+				})
+				continue
+			}
+
+			findUnknownKeys(value, field.Type, keyPath, keys)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yamlv3.SequenceNode {
+			return
+		}
+
+		for index, item := range node.Content {
+			findUnknownKeys(item, t.Elem(), elementPath(path, index, item), keys)
+		}
+	case reflect.Map:
+		if node.Kind != yamlv3.MappingNode {
+			return
+		}
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			findUnknownKeys(node.Content[i+1], t.Elem(), joinPath(path, node.Content[i].Value), keys)
+		}
+	}
+}
+
+// yamlFields maps the keys yaml.v2 uses for a struct to its fields.
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := yamlName(field)
+		if name == "-" {
+			continue
+		}
+
+		fields[name] = field
+	}
+
+	return fields
+}
+
+func yamlName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name
+}
+
+// elementPath identifies an item of a list by its name, when it has one, so
+// paths stay readable in a long metadata file.
+func elementPath(path string, index int, node *yamlv3.Node) string {
+	if node != nil && node.Kind == yamlv3.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "name" && value.Kind == yamlv3.ScalarNode && value.Value != "" {
+				return fmt.Sprintf("%s[name=%s]", path, value.Value)
+			}
+		}
+	}
+
+	return fmt.Sprintf("%s[%d]", path, index)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return fmt.Sprintf("%s.%s", path, key)
+}
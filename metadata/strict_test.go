@@ -0,0 +1,58 @@
+package metadata_test
+
+import (
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parsing metadata", func() {
+	const contents = `---
+name: example
+job_types:
+- name: router
+  freeze_on_deploy: true
+  templates:
+  - name: router
+    relase: routing
+property_blueprints:
+- name: port
+  type: port
+  freeze_on_deploy: true
+  unknown_key: true
+stemcell_criteria:
+  os: ubuntu-xenial
+  enable_patch_security_updates: true
+`
+
+	It("reports all unknown keys with their location", func() {
+		keys, err := metadata.UnknownKeys([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(Equal([]metadata.UnknownKey{
+			{Path: "job_types[name=router].freeze_on_deploy", Line: 5, Column: 3},
+			{Path: "job_types[name=router].templates[name=router].relase", Line: 8, Column: 5},
+			{Path: "property_blueprints[name=port].unknown_key", Line: 13, Column: 3},
+		}))
+	})
+
+	It("fails in strict mode when there are unknown keys", func() {
+		_, err := metadata.Parse([]byte(contents), true)
+		Expect(err).To(MatchError(`found unknown keys in metadata:
+  line 5, column 3: job_types[name=router].freeze_on_deploy
+  line 8, column 5: job_types[name=router].templates[name=router].relase
+  line 13, column 3: property_blueprints[name=port].unknown_key`))
+	})
+
+	It("ignores unknown keys when not strict", func() {
+		payload, err := metadata.Parse([]byte(contents), false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload.Name).To(Equal("example"))
+		Expect(payload.PropertyBlueprints[0].FreeOnDeploy).To(BeTrue())
+	})
+
+	It("parses valid metadata in strict mode", func() {
+		payload, err := metadata.Parse([]byte("{name: example, job_types: [{name: router}]}"), true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(payload.JobTypes[0].Name).To(Equal("router"))
+	})
+})
@@ -0,0 +1,413 @@
+package metadata
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	domainPattern = regexp.MustCompile(`\A([a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?\.)*[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?\z`)
+	uuidPattern   = regexp.MustCompile(`\A[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\z`)
+)
+
+func (pb PropertyBlueprint) ValidateValue(value interface{}) error {
+	if value == nil {
+		if pb.Optional {
+			return nil
+		}
+
+		return fmt.Errorf("a value is required")
+	}
+
+	switch pb.Type {
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("expected a boolean, got %#v", value)
+		}
+	case "integer":
+		return pb.validateInteger(value)
+	case "port":
+		port, ok := integerValue(value)
+		if !ok {
+			return fmt.Errorf("expected a port number, got %#v", value)
+		}
+
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("expected a port between 1 and 65535, got %d", port)
+		}
+
+		return pb.validateInteger(value)
+	case "string", "text", "ca_certificate", "disk_type_dropdown", "vm_type_dropdown",
+		"stemcell_selector", "smtp_authentication", "service_network_az_single_select":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("expected a string, got %#v", value)
+		}
+	case "string_list":
+		if _, ok := value.(string); ok {
+			return nil
+		}
+
+		if _, ok := stringsValue(value); !ok {
+			return fmt.Errorf("expected a comma separated string or a list of strings, got %#v", value)
+		}
+	case "ip_address":
+		return validateString(value, validateIP)
+	case "ip_ranges":
+		return validateString(value, commaSeparated(validateIPRange))
+	case "network_address":
+		return validateString(value, validateNetworkAddress)
+	case "network_address_list":
+		return validateString(value, commaSeparated(validateNetworkAddress))
+	case "email":
+		return validateString(value, validateEmail)
+	case "http_url":
+		return validateString(value, validateURL("http", "https"))
+	case "ldap_url":
+		return validateString(value, validateURL("ldap", "ldaps"))
+	case "domain":
+		return validateString(value, validateDomain)
+	case "wildcard_domain":
+		return validateString(value, commaSeparated(validateWildcardDomain))
+	case "uuid":
+		return validateString(value, validateUUID)
+	case "dropdown_select":
+		return validateString(value, pb.validateOption)
+	case "multi_select_options":
+		options, ok := stringsValue(value)
+		if !ok {
+			return fmt.Errorf("expected a list of options, got %#v", value)
+		}
+
+		for _, option := range options {
+			err := pb.validateOption(option)
+			if err != nil {
+				return err
+			}
+		}
+	case "service_network_az_multi_select":
+		if _, ok := stringsValue(value); !ok {
+			return fmt.Errorf("expected a list of availability zones, got %#v", value)
+		}
+	case "selector":
+		return validateString(value, pb.validateSelectValue)
+	case "collection":
+		return pb.validateCollection(value)
+	case "rsa_cert_credentials":
+		return validateCredential(value, "cert_pem", "private_key_pem")
+	case "rsa_pkey_credentials":
+		return validateCredential(value, "private_key_pem")
+	case "simple_credentials", "salted_credentials":
+		return validateCredential(value, "identity", "password")
+	case "secret":
+		return validateCredential(value, "secret")
+	}
+
+	return nil
+}
+
+func (pb PropertyBlueprint) validateInteger(value interface{}) error {
+	number, ok := integerValue(value)
+	if !ok {
+		return fmt.Errorf("expected an integer, got %#v", value)
+	}
+
+	for _, constraints := range pb.Constraints {
+		err := constraints.Validate(number)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c Constraints) Validate(number int) error {
+	if c.ZeroOrMin != 0 && number != 0 && number < c.ZeroOrMin {
+		return fmt.Errorf("expected zero or at least %d, got %d", c.ZeroOrMin, number)
+	}
+
+	if c.Min != 0 && number < c.Min {
+		return fmt.Errorf("expected at least %d, got %d", c.Min, number)
+	}
+
+	if c.Max != 0 && number > c.Max {
+		return fmt.Errorf("expected at most %d, got %d", c.Max, number)
+	}
+
+	if c.Modulo != 0 && number%c.Modulo != 0 {
+		return fmt.Errorf("expected a multiple of %d, got %d", c.Modulo, number)
+	}
+
+	if c.PowerOfTwo && (number <= 0 || number&(number-1) != 0) {
+		return fmt.Errorf("expected a power of two, got %d", number)
+	}
+
+	if c.MaxOnlyBeOddOrZero && number != 0 && number%2 == 0 {
+		return fmt.Errorf("expected an odd number or zero, got %d", number)
+	}
+
+	return nil
+}
+
+func (pb PropertyBlueprint) validateOption(value string) error {
+	names := []string{}
+	for _, option := range pb.Options {
+		if option.Name == value {
+			return nil
+		}
+		names = append(names, option.Name)
+	}
+
+	return fmt.Errorf("expected one of [%s], got %q", strings.Join(names, " "), value)
+}
+
+func (pb PropertyBlueprint) validateSelectValue(value string) error {
+	values := []string{}
+	for _, optionTemplate := range pb.OptionTemplates {
+		if optionTemplate.SelectValue == value || optionTemplate.Name == value {
+			return nil
+		}
+		values = append(values, optionTemplate.SelectValue)
+	}
+
+	return fmt.Errorf("expected one of [%s], got %q", strings.Join(values, " "), value)
+}
+
+func (pb PropertyBlueprint) validateCollection(value interface{}) error {
+	entries, ok := listValue(value)
+	if !ok {
+		return fmt.Errorf("expected a list of entries, got %#v", value)
+	}
+
+	for index, entry := range entries {
+		fields, ok := stringMap(entry)
+		if !ok {
+			return fmt.Errorf("entry %d: expected a map, got %#v", index, entry)
+		}
+
+		known := map[string]bool{}
+		for _, nested := range pb.PropertyBlueprints {
+			known[nested.Name] = true
+
+			err := nested.ValidateValue(fields[nested.Name])
+			if err != nil {
+				return fmt.Errorf("entry %d: field '%s': %s", index, nested.Name, err)
+			}
+		}
+
+		unknown := []string{}
+		for name := range fields {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+
+		if len(unknown) > 0 {
+			sort.Strings(unknown)
+			return fmt.Errorf("entry %d: unknown field(s) '%s'", index, strings.Join(unknown, "', '"))
+		}
+	}
+
+	return nil
+}
+
+func validateCredential(value interface{}, keys ...string) error {
+	fields, ok := stringMap(value)
+	if !ok {
+		return fmt.Errorf("expected a map with '%s', got %#v", strings.Join(keys, "', '"), value)
+	}
+
+	for _, key := range keys {
+		field, ok := fields[key].(string)
+		if !ok || field == "" {
+			return fmt.Errorf("expected '%s' to be set", key)
+		}
+	}
+
+	return nil
+}
+
+func validateString(value interface{}, validate func(string) error) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("expected a string, got %#v", value)
+	}
+
+	return validate(s)
+}
+
+func commaSeparated(validate func(string) error) func(string) error {
+	return func(value string) error {
+		for _, item := range strings.Split(value, ",") {
+			err := validate(strings.TrimSpace(item))
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+func validateIP(value string) error {
+	if net.ParseIP(value) == nil {
+		return fmt.Errorf("expected an IP address, got %q", value)
+	}
+
+	return nil
+}
+
+func validateIPRange(value string) error {
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return nil
+	}
+
+	parts := strings.Split(value, "-")
+	if len(parts) > 2 {
+		return fmt.Errorf("expected an IP address, range or CIDR, got %q", value)
+	}
+
+	for _, part := range parts {
+		if net.ParseIP(strings.TrimSpace(part)) == nil {
+			return fmt.Errorf("expected an IP address, range or CIDR, got %q", value)
+		}
+	}
+
+	return nil
+}
+
+func validateNetworkAddress(value string) error {
+	if net.ParseIP(value) != nil || domainPattern.MatchString(value) {
+		return nil
+	}
+
+	return fmt.Errorf("expected an IP address or hostname, got %q", value)
+}
+
+func validateEmail(value string) error {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return fmt.Errorf("expected an email address, got %q", value)
+	}
+
+	return nil
+}
+
+func validateURL(schemes ...string) func(string) error {
+	return func(value string) error {
+		u, err := url.Parse(value)
+		if err == nil && u.Host != "" {
+			for _, scheme := range schemes {
+				if u.Scheme == scheme {
+					return nil
+				}
+			}
+		}
+
+		return fmt.Errorf("expected a %s URL, got %q", strings.Join(schemes, " or "), value)
+	}
+}
+
+func validateDomain(value string) error {
+	if !domainPattern.MatchString(value) {
+		return fmt.Errorf("expected a domain, got %q", value)
+	}
+
+	return nil
+}
+
+func validateWildcardDomain(value string) error {
+	if !strings.HasPrefix(value, "*.") || !domainPattern.MatchString(strings.TrimPrefix(value, "*.")) {
+		return fmt.Errorf("expected a wildcard domain (*.example.com), got %q", value)
+	}
+
+	return nil
+}
+
+func validateUUID(value string) error {
+	if !uuidPattern.MatchString(value) {
+		return fmt.Errorf("expected a UUID, got %q", value)
+	}
+
+	return nil
+}
+
+func integerValue(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		if v == math.Trunc(v) {
+			return int(v), true
+		}
+	}
+
+	return 0, false
+}
+
+func stringsValue(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []interface{}:
+		list := []string{}
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, s)
+		}
+		return list, true
+	}
+
+	return nil, false
+}
+
+// listValue accepts any slice, like the []map[string]interface{} of a
+// generated collection default.
+func listValue(value interface{}) ([]interface{}, bool) {
+	if list, ok := value.([]interface{}); ok {
+		return list, true
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice {
+		return nil, false
+	}
+
+	list := make([]interface{}, v.Len())
+	for i := range list {
+		list[i] = v.Index(i).Interface()
+	}
+
+	return list, true
+}
+
+func stringMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		fields := map[string]interface{}{}
+		for key, item := range v {
+			fields[fmt.Sprintf("%v", key)] = item
+		}
+		return fields, true
+	}
+
+	return nil, false
+}
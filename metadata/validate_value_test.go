@@ -0,0 +1,155 @@
+package metadata_test
+
+import (
+	"github.com/jtarchie/tile-builder/metadata"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Validating a property blueprint's value", func() {
+	DescribeTable("valid values", func(pb metadata.PropertyBlueprint, value interface{}) {
+		Expect(pb.ValidateValue(value)).To(Succeed())
+	},
+		Entry("optional without a value", metadata.PropertyBlueprint{Type: "string", Optional: true}, nil),
+		Entry("boolean", metadata.PropertyBlueprint{Type: "boolean"}, true),
+		Entry("integer", metadata.PropertyBlueprint{Type: "integer"}, 10),
+		Entry("integer as a whole float", metadata.PropertyBlueprint{Type: "integer"}, 10.0),
+		Entry("port", metadata.PropertyBlueprint{Type: "port"}, 8080),
+		Entry("string", metadata.PropertyBlueprint{Type: "string"}, "value"),
+		Entry("text", metadata.PropertyBlueprint{Type: "text"}, "value"),
+		Entry("string_list as a string", metadata.PropertyBlueprint{Type: "string_list"}, "a,b"),
+		Entry("string_list as a list", metadata.PropertyBlueprint{Type: "string_list"}, []interface{}{"a", "b"}),
+		Entry("ip_address", metadata.PropertyBlueprint{Type: "ip_address"}, "10.0.0.1"),
+		Entry("ip_ranges", metadata.PropertyBlueprint{Type: "ip_ranges"}, "10.0.0.1,10.0.0.5-10.0.0.10,10.0.1.0/24"),
+		Entry("network_address", metadata.PropertyBlueprint{Type: "network_address"}, "host.example.com"),
+		Entry("network_address_list", metadata.PropertyBlueprint{Type: "network_address_list"}, "10.0.0.1, host.example.com"),
+		Entry("email", metadata.PropertyBlueprint{Type: "email"}, "user@example.com"),
+		Entry("http_url", metadata.PropertyBlueprint{Type: "http_url"}, "https://example.com/path"),
+		Entry("ldap_url", metadata.PropertyBlueprint{Type: "ldap_url"}, "ldaps://ldap.example.com:636"),
+		Entry("domain", metadata.PropertyBlueprint{Type: "domain"}, "sys.example.com"),
+		Entry("wildcard_domain", metadata.PropertyBlueprint{Type: "wildcard_domain"}, "*.sys.example.com,*.apps.example.com"),
+		Entry("uuid", metadata.PropertyBlueprint{Type: "uuid"}, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"),
+		Entry("dropdown_select", metadata.PropertyBlueprint{
+			Type:    "dropdown_select",
+			Options: []metadata.Option{{Name: "a"}, {Name: "b"}},
+		}, "b"),
+		Entry("multi_select_options", metadata.PropertyBlueprint{
+			Type:    "multi_select_options",
+			Options: []metadata.Option{{Name: "a"}, {Name: "b"}},
+		}, []interface{}{"a", "b"}),
+		Entry("selector", metadata.PropertyBlueprint{
+			Type:            "selector",
+			OptionTemplates: []metadata.OptionTemplate{{Name: "enabled", SelectValue: "Enabled"}},
+		}, "Enabled"),
+		Entry("collection", metadata.PropertyBlueprint{
+			Type: "collection",
+			PropertyBlueprints: []metadata.PropertyBlueprint{
+				{Name: "name", Type: "string"},
+				{Name: "port", Type: "port", Optional: true},
+			},
+		}, []interface{}{
+			map[interface{}]interface{}{"name": "a", "port": 80},
+			map[interface{}]interface{}{"name": "b"},
+		}),
+		Entry("collection of typed entries", metadata.PropertyBlueprint{
+			Type:               "collection",
+			PropertyBlueprints: []metadata.PropertyBlueprint{{Name: "name", Type: "string"}},
+		}, []map[string]interface{}{{"name": "a"}}),
+		Entry("rsa_cert_credentials", metadata.PropertyBlueprint{Type: "rsa_cert_credentials"}, map[interface{}]interface{}{
+			"cert_pem":        "cert",
+			"private_key_pem": "key",
+		}),
+		Entry("rsa_pkey_credentials", metadata.PropertyBlueprint{Type: "rsa_pkey_credentials"}, map[interface{}]interface{}{
+			"private_key_pem": "key",
+		}),
+		Entry("simple_credentials", metadata.PropertyBlueprint{Type: "simple_credentials"}, map[interface{}]interface{}{
+			"identity": "user",
+			"password": "password",
+		}),
+		Entry("secret", metadata.PropertyBlueprint{Type: "secret"}, map[interface{}]interface{}{
+			"secret": "password",
+		}),
+		Entry("within min and max", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{Min: 1, Max: 10}},
+		}, 10),
+		Entry("a multiple of modulo", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{Modulo: 4}},
+		}, 8),
+		Entry("zero when zero or min", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{ZeroOrMin: 3}},
+		}, 0),
+	)
+
+	DescribeTable("invalid values", func(pb metadata.PropertyBlueprint, value interface{}, message string) {
+		Expect(pb.ValidateValue(value)).To(MatchError(ContainSubstring(message)))
+	},
+		Entry("required without a value", metadata.PropertyBlueprint{Type: "string"}, nil, "a value is required"),
+		Entry("boolean", metadata.PropertyBlueprint{Type: "boolean"}, "true", "expected a boolean"),
+		Entry("integer", metadata.PropertyBlueprint{Type: "integer"}, "1", "expected an integer"),
+		Entry("integer as a fraction", metadata.PropertyBlueprint{Type: "integer"}, 1.5, "expected an integer"),
+		Entry("port out of range", metadata.PropertyBlueprint{Type: "port"}, 70000, "between 1 and 65535"),
+		Entry("string", metadata.PropertyBlueprint{Type: "string"}, 1, "expected a string"),
+		Entry("string_list", metadata.PropertyBlueprint{Type: "string_list"}, []interface{}{1}, "list of strings"),
+		Entry("ip_address", metadata.PropertyBlueprint{Type: "ip_address"}, "10.0.0", "expected an IP address"),
+		Entry("ip_ranges", metadata.PropertyBlueprint{Type: "ip_ranges"}, "10.0.0.1-10.0.0", "IP address, range or CIDR"),
+		Entry("network_address", metadata.PropertyBlueprint{Type: "network_address"}, "not a host", "IP address or hostname"),
+		Entry("email", metadata.PropertyBlueprint{Type: "email"}, "user", "expected an email address"),
+		Entry("http_url", metadata.PropertyBlueprint{Type: "http_url"}, "ftp://example.com", "http or https URL"),
+		Entry("ldap_url", metadata.PropertyBlueprint{Type: "ldap_url"}, "https://example.com", "ldap or ldaps URL"),
+		Entry("domain", metadata.PropertyBlueprint{Type: "domain"}, "-example.com", "expected a domain"),
+		Entry("wildcard_domain", metadata.PropertyBlueprint{Type: "wildcard_domain"}, "example.com", "expected a wildcard domain"),
+		Entry("uuid", metadata.PropertyBlueprint{Type: "uuid"}, "not-a-uuid", "expected a UUID"),
+		Entry("dropdown_select", metadata.PropertyBlueprint{
+			Type:    "dropdown_select",
+			Options: []metadata.Option{{Name: "a"}, {Name: "b"}},
+		}, "c", `expected one of [a b], got "c"`),
+		Entry("multi_select_options", metadata.PropertyBlueprint{
+			Type:    "multi_select_options",
+			Options: []metadata.Option{{Name: "a"}},
+		}, []interface{}{"a", "c"}, `expected one of [a], got "c"`),
+		Entry("selector", metadata.PropertyBlueprint{
+			Type:            "selector",
+			OptionTemplates: []metadata.OptionTemplate{{Name: "enabled", SelectValue: "Enabled"}},
+		}, "Disabled", `expected one of [Enabled], got "Disabled"`),
+		Entry("collection entry", metadata.PropertyBlueprint{
+			Type:               "collection",
+			PropertyBlueprints: []metadata.PropertyBlueprint{{Name: "port", Type: "port"}},
+		}, []interface{}{map[interface{}]interface{}{"port": "80"}}, "entry 0: field 'port': expected a port number"),
+		Entry("collection unknown field", metadata.PropertyBlueprint{
+			Type:               "collection",
+			PropertyBlueprints: []metadata.PropertyBlueprint{{Name: "name", Type: "string"}},
+		}, []interface{}{map[interface{}]interface{}{"name": "a", "nmae": "b"}}, "entry 0: unknown field(s) 'nmae'"),
+		Entry("rsa_cert_credentials", metadata.PropertyBlueprint{Type: "rsa_cert_credentials"}, map[interface{}]interface{}{
+			"cert_pem": "cert",
+		}, "expected 'private_key_pem' to be set"),
+		Entry("simple_credentials", metadata.PropertyBlueprint{Type: "simple_credentials"}, "password", "expected a map with 'identity', 'password'"),
+		Entry("below min", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{Min: 5}},
+		}, 4, "expected at least 5, got 4"),
+		Entry("above max", metadata.PropertyBlueprint{
+			Type:        "port",
+			Constraints: []metadata.Constraints{{Max: 1024}},
+		}, 8080, "expected at most 1024, got 8080"),
+		Entry("not a multiple of modulo", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{Modulo: 4}},
+		}, 6, "expected a multiple of 4, got 6"),
+		Entry("not a power of two", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{PowerOfTwo: true}},
+		}, 6, "expected a power of two, got 6"),
+		Entry("not odd or zero", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{MaxOnlyBeOddOrZero: true}},
+		}, 2, "expected an odd number or zero, got 2"),
+		Entry("not zero or min", metadata.PropertyBlueprint{
+			Type:        "integer",
+			Constraints: []metadata.Constraints{{ZeroOrMin: 3}},
+		}, 2, "expected zero or at least 3, got 2"),
+	)
+})
@@ -2,6 +2,7 @@ package metadata
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 
 	"github.com/go-playground/locales/en"
@@ -44,15 +45,30 @@ func (p Payload) Validate() (validator.ValidationErrorsTranslations, error) {
 		return nil, err
 	}
 
+	validations := validator.ValidationErrorsTranslations{}
+
 	err = validate.Struct(p)
 	if errs, ok := err.(validator.ValidationErrors); ok {
-		return errs.Translate(trans), nil
+		validations = errs.Translate(trans)
+	} else if err != nil {
+		return nil, err
+	}
+
+	for field, message := range p.semanticValidations() {
+		if _, found := validations[field]; !found {
+			validations[field] = message
+		}
 	}
-	return nil, err
+
+	return validations, nil
 }
 
 func (p Payload) FindPropertyBlueprintFromPropertyInput(reference string) (PropertyBlueprint, bool) {
 	parts := strings.Split(reference, ".")
+	if len(parts) < 2 {
+		return PropertyBlueprint{}, false
+	}
+
 	if parts[1] == "properties" {
 		return propertyBlueprint(".properties", reference, p.PropertyBlueprints)
 	}
@@ -79,6 +95,10 @@ func propertyBlueprint(prefix string, reference string, blueprints []PropertyBlu
 			if len(pb.OptionTemplates) > 0 {
 				for _, optionTemplate := range pb.OptionTemplates {
 					optionTemplatePrefix := fmt.Sprintf("%s.%s.%s", prefix, pb.Name, optionTemplate.Name)
+					// selector property inputs reference the option template itself
+					if optionTemplatePrefix == reference {
+						return pb, true
+					}
 					pb, found := propertyBlueprint(optionTemplatePrefix, reference, optionTemplate.PropertyBlueprints)
 					if found {
 						return pb, found
@@ -92,3 +112,60 @@ func propertyBlueprint(prefix string, reference string, blueprints []PropertyBlu
 
 	return PropertyBlueprint{}, false
 }
+
+// ValidPropertyBlueprintType is true for the types a property blueprint is
+// validated against.
+func ValidPropertyBlueprintType(pbType string) bool {
+	field, _ := reflect.TypeOf(PropertyBlueprint{}).FieldByName("Type")
+
+	for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+		if strings.HasPrefix(rule, "oneof=") {
+			for _, valid := range strings.Fields(strings.TrimPrefix(rule, "oneof=")) {
+				if valid == pbType {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func (p Payload) RequiresServiceNetwork() bool {
+	if p.ServiceBroker {
+		return true
+	}
+
+	if usesServiceNetwork(p.PropertyBlueprints) {
+		return true
+	}
+
+	for _, jobType := range p.JobTypes {
+		if usesServiceNetwork(jobType.PropertyBlueprints) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func usesServiceNetwork(blueprints []PropertyBlueprint) bool {
+	for _, pb := range blueprints {
+		switch pb.Type {
+		case "service_network_az_multi_select", "service_network_az_single_select":
+			return true
+		}
+
+		if usesServiceNetwork(pb.PropertyBlueprints) {
+			return true
+		}
+
+		for _, optionTemplate := range pb.OptionTemplates {
+			if usesServiceNetwork(optionTemplate.PropertyBlueprints) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
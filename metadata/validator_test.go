@@ -74,4 +74,91 @@ var _ = Describe("Validating a tile's metadata", func() {
 			"References a property blueprint ('.properties.name') that does not exist",
 		))
 	})
+
+	It("checks references between parts of the payload", func() {
+		payload := metadata.Payload{
+			Releases:         []metadata.Release{{Name: "my-release"}},
+			StemcellCriteria: metadata.StemcellCriteria{OS: "ubuntu-xenial"},
+			JobTypes: []metadata.JobType{
+				{
+					Name:        "web",
+					UseStemcell: "windows2019",
+					Templates: []metadata.Template{
+						{Name: "web", Release: "my-release"},
+						{Name: "worker", Release: "other-release"},
+					},
+					InstanceDefinition: metadata.InstanceDefinition{
+						ZeroIf: metadata.ZeroIf{PropertyReference: ".properties.missing"},
+					},
+				},
+				{Name: "web"},
+			},
+			PropertyBlueprints: []metadata.PropertyBlueprint{
+				{Name: "port", Type: "port"},
+				{Name: "port", Type: "port"},
+			},
+			PostDeployErrands: []metadata.Errand{
+				{Name: "smoke-tests", Instances: []string{"web/first", "database/0"}},
+			},
+			RequiresProductVersions: []metadata.ProductVersion{
+				{Name: "cf", Version: "~> 2.7"},
+				{Name: "p-mysql", Version: ">= 2.6.0, < 3"},
+				{Name: "p-redis", Version: "latest"},
+				{Name: "p-rabbitmq", Version: ">= 1.0 < 2.0"},
+				{Name: "p-spring-cloud", Version: "> 1.0 <= 2.0 || ~> 3.1"},
+				{Name: "p-healthwatch", Version: ">= 1.0 <"},
+			},
+		}
+		messages, err := payload.Validate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages).To(HaveKeyWithValue("Payload.JobTypes[0].Templates[1].Release", "References a release ('other-release') that does not exist"))
+		Expect(messages).To(HaveKeyWithValue("Payload.JobTypes[0].UseStemcell", "References a stemcell OS ('windows2019') that is not in the stemcell criteria"))
+		Expect(messages).To(HaveKeyWithValue("Payload.JobTypes[0].InstanceDefinition.ZeroIf.PropertyReference", "References a property blueprint ('.properties.missing') that does not exist"))
+		Expect(messages).To(HaveKeyWithValue("Payload.JobTypes[1].Name", "Name ('web') is already used by another job type"))
+		Expect(messages).To(HaveKeyWithValue("Payload.PropertyBlueprints[1].Name", "Name ('port') is already used by another property blueprint"))
+		Expect(messages).To(HaveKeyWithValue("Payload.PostDeployErrands[0].Instances[1]", "References a job type ('database') that does not exist"))
+		Expect(messages).To(HaveKeyWithValue("Payload.RequiresProductVersions[2].Version", "Version ('latest') is not a valid version constraint"))
+
+		Expect(messages).NotTo(HaveKey("Payload.JobTypes[0].Templates[0].Release"))
+		Expect(messages).NotTo(HaveKey("Payload.PostDeployErrands[0].Instances[0]"))
+		Expect(messages).NotTo(HaveKey("Payload.RequiresProductVersions[0].Version"))
+		Expect(messages).NotTo(HaveKey("Payload.RequiresProductVersions[1].Version"))
+		Expect(messages).NotTo(HaveKey("Payload.RequiresProductVersions[3].Version"))
+		Expect(messages).NotTo(HaveKey("Payload.RequiresProductVersions[4].Version"))
+		Expect(messages).To(HaveKeyWithValue("Payload.RequiresProductVersions[5].Version", "Version ('>= 1.0 <') is not a valid version constraint"))
+	})
+
+	It("allows a selector property input to reference an option template", func() {
+		payload := metadata.Payload{
+			FormTypes: []metadata.FormType{
+				{
+					PropertyInputs: []metadata.PropertyInput{
+						{
+							Reference: ".properties.tls",
+							SelectorPropertyInputs: []metadata.PropertyInput{
+								{Reference: ".properties.tls.enabled"},
+								{Reference: ".properties.tls.missing"},
+							},
+						},
+					},
+				},
+			},
+			PropertyBlueprints: []metadata.PropertyBlueprint{
+				{
+					Name: "tls",
+					Type: "selector",
+					OptionTemplates: []metadata.OptionTemplate{
+						{Name: "enabled", SelectValue: "enabled"},
+					},
+				},
+			},
+		}
+		messages, err := payload.Validate()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messages).NotTo(HaveKey("Payload.FormTypes[0].PropertyInputs[0].SelectorPropertyInputs[0].Reference"))
+		Expect(messages).To(HaveKeyWithValue(
+			"Payload.FormTypes[0].PropertyInputs[0].SelectorPropertyInputs[1].Reference",
+			"References a property blueprint ('.properties.tls.missing') that does not exist",
+		))
+	})
 })
@@ -0,0 +1,335 @@
+package migrations
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/jtarchie/tile-builder/metadata"
+)
+
+type ChangeKind string
+
+const (
+	Renamed ChangeKind = "renamed"
+	Retyped ChangeKind = "retyped"
+	Removed ChangeKind = "removed"
+	// Suggested is a removed blueprint that may have been renamed, as an added
+	// blueprint has the same type and default. It is only reported, the value
+	// is moved when the rename is confirmed.
+	Suggested ChangeKind = "suggested"
+)
+
+// Change is a property blueprint, by its reference, that changed between two
+// versions of a tile.
+type Change struct {
+	Kind     ChangeKind
+	From     string
+	To       string
+	FromType string
+	ToType   string
+}
+
+func (c Change) String() string {
+	switch c.Kind {
+	case Renamed:
+		return fmt.Sprintf("%s was renamed to %s", c.From, c.To)
+	case Retyped:
+		return fmt.Sprintf("%s was retyped from %s to %s", c.From, c.FromType, c.ToType)
+	case Suggested:
+		return fmt.Sprintf("%s may have been renamed to %s", c.From, c.To)
+	}
+
+	return fmt.Sprintf("%s was removed", c.From)
+}
+
+// NeedsAttention is true when the value of the property cannot be migrated,
+// because it was removed or its type holds a different kind of value.
+func (c Change) NeedsAttention() bool {
+	switch c.Kind {
+	case Removed:
+		return true
+	case Retyped:
+		return valueKind(c.FromType) == "" || valueKind(c.FromType) != valueKind(c.ToType)
+	}
+
+	return false
+}
+
+// valueKind groups the types of property blueprints whose values are
+// interchangeable.
+func valueKind(pbType string) string {
+	switch pbType {
+	case "string", "text", "domain", "wildcard_domain", "email", "http_url", "ldap_url", "ip_address", "ip_ranges", "network_address", "network_address_list", "uuid":
+		return "string"
+	case "integer", "port":
+		return "integer"
+	}
+
+	return ""
+}
+
+// Compare finds the property blueprints that were renamed, retyped or removed,
+// including the blueprints of selector options and the fields of collection
+// entries. The renames map old references to new ones. Other renames are
+// detected when a removed blueprint matches exactly one added blueprint by
+// name, and the other way around, like a blueprint moved to a job. A match by
+// type and default is only suggested.
+func Compare(before, after metadata.Payload, renames map[string]string) ([]Change, error) {
+	old := blueprints(before)
+	current := blueprints(after)
+
+	var changes []Change
+
+	renamed := map[string]bool{}
+	// moved maps the references of renamed blueprints to their new ones
+	moved := map[string]string{}
+
+	for from, to := range renames {
+		if _, found := old[from]; !found {
+			return nil, fmt.Errorf("renamed property %s does not exist in the previous tile", from)
+		}
+
+		if _, found := current[to]; !found {
+			return nil, fmt.Errorf("renamed property %s does not exist in the current tile", to)
+		}
+
+		changes = append(changes, rename(from, to, old[from], current[to])...)
+		renamed[from], renamed[to] = true, true
+		moved[from] = to
+	}
+
+	var removed, added []string
+
+	for reference := range old {
+		if _, found := current[reference]; !found && !renamed[reference] {
+			removed = append(removed, reference)
+		}
+	}
+
+	for reference := range current {
+		if _, found := old[reference]; !found && !renamed[reference] {
+			added = append(added, reference)
+		}
+	}
+
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	for _, from := range removed {
+		if renamed[from] {
+			continue
+		}
+
+		to, ok := uniqueMatch(from, removed, added, renamed, func(from, to string) bool {
+			return blueprintName(from) == blueprintName(to)
+		})
+		if !ok {
+			continue
+		}
+
+		changes = append(changes, rename(from, to, old[from], current[to])...)
+		renamed[from], renamed[to] = true, true
+		moved[from] = to
+	}
+
+	suggested := map[string]bool{}
+	for reference := range renamed {
+		suggested[reference] = true
+	}
+
+	for _, from := range removed {
+		if suggested[from] {
+			continue
+		}
+
+		to, ok := uniqueMatch(from, removed, added, suggested, func(from, to string) bool {
+			return old[from].Default != nil &&
+				old[from].Type == current[to].Type &&
+				reflect.DeepEqual(old[from].Default, current[to].Default)
+		})
+		if !ok {
+			continue
+		}
+
+		changes = append(changes, Change{Kind: Suggested, From: from, To: to, FromType: old[from].Type, ToType: current[to].Type})
+		suggested[from], suggested[to] = true, true
+	}
+
+	for _, reference := range removed {
+		if !renamed[reference] {
+			changes = append(changes, Change{Kind: Removed, From: reference, FromType: old[reference].Type})
+		}
+	}
+
+	for reference, pb := range old {
+		if other, found := current[reference]; found && pb.Type != other.Type {
+			changes = append(changes, Change{Kind: Retyped, From: reference, To: reference, FromType: pb.Type, ToType: other.Type})
+		}
+	}
+
+	for from, pb := range old {
+		to, found := moved[from]
+		if !found {
+			to = from
+		}
+
+		if other, found := current[to]; found {
+			changes = append(changes, entryChanges(from, to, pb, other)...)
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].From != changes[j].From {
+			return changes[i].From < changes[j].From
+		}
+
+		return changes[i].Kind < changes[j].Kind
+	})
+
+	return changes, nil
+}
+
+// entryChanges finds the fields of a collection's entries that were removed or
+// retyped. The fields are not properties of their own, so they are never
+// renamed.
+func entryChanges(from, to string, before, after metadata.PropertyBlueprint) []Change {
+	if before.Type != "collection" || after.Type != "collection" {
+		return nil
+	}
+
+	fields := map[string]metadata.PropertyBlueprint{}
+	for _, field := range after.PropertyBlueprints {
+		fields[field.Name] = field
+	}
+
+	var changes []Change
+
+	for _, field := range before.PropertyBlueprints {
+		reference := fmt.Sprintf("%s.%s", from, field.Name)
+
+		other, found := fields[field.Name]
+		if !found {
+			changes = append(changes, Change{Kind: Removed, From: reference, FromType: field.Type})
+			continue
+		}
+
+		if field.Type != other.Type {
+			changes = append(changes, Change{Kind: Retyped, From: reference, To: fmt.Sprintf("%s.%s", to, field.Name), FromType: field.Type, ToType: other.Type})
+		}
+	}
+
+	return changes
+}
+
+func rename(from, to string, before, after metadata.PropertyBlueprint) []Change {
+	changes := []Change{{Kind: Renamed, From: from, To: to, FromType: before.Type, ToType: after.Type}}
+
+	if before.Type != after.Type {
+		changes = append(changes, Change{Kind: Retyped, From: from, To: to, FromType: before.Type, ToType: after.Type})
+	}
+
+	return changes
+}
+
+func uniqueMatch(from string, removed, added []string, renamed map[string]bool, matches func(from, to string) bool) (string, bool) {
+	var candidates []string
+
+	for _, to := range added {
+		if !renamed[to] && matches(from, to) {
+			candidates = append(candidates, to)
+		}
+	}
+
+	if len(candidates) != 1 {
+		return "", false
+	}
+
+	for _, other := range removed {
+		if other != from && !renamed[other] && matches(other, candidates[0]) {
+			return "", false
+		}
+	}
+
+	return candidates[0], true
+}
+
+func blueprints(payload metadata.Payload) map[string]metadata.PropertyBlueprint {
+	references := map[string]metadata.PropertyBlueprint{}
+
+	for _, pb := range payload.PropertyBlueprints {
+		addBlueprint(references, fmt.Sprintf(".properties.%s", pb.Name), pb)
+	}
+
+	for _, jobType := range payload.JobTypes {
+		for _, pb := range jobType.PropertyBlueprints {
+			addBlueprint(references, fmt.Sprintf(".%s.%s", jobType.Name, pb.Name), pb)
+		}
+	}
+
+	return references
+}
+
+// addBlueprint adds the blueprint, and the blueprints of its selector options,
+// which are properties of their own, e.g. `.properties.tls.enabled.cert`.
+func addBlueprint(references map[string]metadata.PropertyBlueprint, reference string, pb metadata.PropertyBlueprint) {
+	references[reference] = pb
+
+	for _, option := range pb.OptionTemplates {
+		for _, optionPB := range option.PropertyBlueprints {
+			addBlueprint(references, fmt.Sprintf("%s.%s.%s", reference, option.Name, optionPB.Name), optionPB)
+		}
+	}
+}
+
+func blueprintName(reference string) string {
+	return reference[strings.LastIndex(reference, ".")+1:]
+}
+
+// Script is the Ops Manager migration moving the values of the renamed
+// property blueprints, and noting the changes that need attention. It is
+// empty when nothing was renamed or needs attention.
+func Script(changes []Change) []byte {
+	var (
+		body   bytes.Buffer
+		needed bool
+	)
+
+	for _, change := range changes {
+		if change.NeedsAttention() {
+			needed = true
+
+			_, _ = fmt.Fprintf(&body, "  // TODO: %s, its value has to be migrated by hand\n", change)
+		}
+	}
+
+	for _, change := range changes {
+		if change.Kind != Renamed {
+			continue
+		}
+
+		needed = true
+
+		_, _ = fmt.Fprintf(&body, "  // %s\n", change)
+		_, _ = fmt.Fprintf(&body, "  if (properties['%s'] !== undefined) {\n", change.From)
+		_, _ = fmt.Fprintf(&body, "    properties['%s'] = properties['%s'];\n", change.To, change.From)
+		_, _ = fmt.Fprintf(&body, "    delete properties['%s'];\n", change.From)
+		_, _ = fmt.Fprintf(&body, "  }\n")
+	}
+
+	if !needed {
+		return nil
+	}
+
+	var script bytes.Buffer
+
+	_, _ = fmt.Fprintf(&script, "exports.migrate = function(input) {\n")
+	_, _ = fmt.Fprintf(&script, "  var properties = input.properties;\n\n")
+	_, _ = script.Write(body.Bytes())
+	_, _ = fmt.Fprintf(&script, "\n  return input;\n")
+	_, _ = fmt.Fprintf(&script, "};\n")
+
+	return script.Bytes()
+}
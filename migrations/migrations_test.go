@@ -0,0 +1,177 @@
+package migrations_test
+
+import (
+	"github.com/jtarchie/tile-builder/metadata"
+	"github.com/jtarchie/tile-builder/migrations"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v2"
+)
+
+var _ = Describe("Migrations", func() {
+	payload := func(contents string) metadata.Payload {
+		var payload metadata.Payload
+
+		err := yaml.Unmarshal([]byte(contents), &payload)
+		Expect(err).NotTo(HaveOccurred())
+
+		return payload
+	}
+
+	var before, after metadata.Payload
+
+	BeforeEach(func() {
+		before = payload(`
+property_blueprints:
+- {name: port, type: integer, default: 8080}
+- {name: log_level, type: string, default: info}
+- {name: timeout, type: integer, default: 30}
+- {name: hostname, type: string}
+- {name: enabled, type: boolean, default: true}
+- {name: legacy, type: string}
+job_types:
+- name: web
+`)
+		after = payload(`
+property_blueprints:
+- {name: listen_port, type: port, default: 8080}
+- {name: logging_level, type: string, default: info}
+- {name: timeout, type: string, default: "30"}
+- {name: hostname, type: text}
+- {name: enabled, type: boolean, default: true}
+job_types:
+- name: web
+  property_blueprints:
+  - {name: legacy, type: string}
+`)
+	})
+
+	It("finds renamed, retyped and removed property blueprints", func() {
+		changes, err := migrations.Compare(before, after, map[string]string{
+			".properties.port": ".properties.listen_port",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(changes).To(Equal([]migrations.Change{
+			{Kind: migrations.Retyped, From: ".properties.hostname", To: ".properties.hostname", FromType: "string", ToType: "text"},
+			{Kind: migrations.Renamed, From: ".properties.legacy", To: ".web.legacy", FromType: "string", ToType: "string"},
+			{Kind: migrations.Removed, From: ".properties.log_level", FromType: "string"},
+			{Kind: migrations.Suggested, From: ".properties.log_level", To: ".properties.logging_level", FromType: "string", ToType: "string"},
+			{Kind: migrations.Renamed, From: ".properties.port", To: ".properties.listen_port", FromType: "integer", ToType: "port"},
+			{Kind: migrations.Retyped, From: ".properties.port", To: ".properties.listen_port", FromType: "integer", ToType: "port"},
+			{Kind: migrations.Retyped, From: ".properties.timeout", To: ".properties.timeout", FromType: "integer", ToType: "string"},
+		}))
+
+		Expect(changes[0].NeedsAttention()).To(BeFalse())
+		Expect(changes[3].NeedsAttention()).To(BeFalse())
+		Expect(changes[3].String()).To(Equal(".properties.log_level may have been renamed to .properties.logging_level"))
+		Expect(changes[5].NeedsAttention()).To(BeFalse())
+		Expect(changes[6].NeedsAttention()).To(BeTrue())
+		Expect(changes[6].String()).To(Equal(".properties.timeout was retyped from integer to string"))
+	})
+
+	It("compares the blueprints of selector options and collection entries", func() {
+		changes, err := migrations.Compare(payload(`
+property_blueprints:
+- name: tls
+  type: selector
+  option_templates:
+  - name: enabled
+    property_blueprints:
+    - {name: cert, type: rsa_cert_credentials}
+- name: routes
+  type: collection
+  property_blueprints:
+  - {name: host, type: string}
+  - {name: port, type: integer}
+  - {name: weight, type: integer}
+`), payload(`
+property_blueprints:
+- name: tls
+  type: selector
+  option_templates:
+  - name: enabled
+    property_blueprints:
+    - {name: certificate, type: rsa_cert_credentials}
+job_types:
+- name: web
+  property_blueprints:
+  - name: routes
+    type: collection
+    property_blueprints:
+    - {name: host, type: string}
+    - {name: port, type: string}
+`), map[string]string{".properties.tls.enabled.cert": ".properties.tls.enabled.certificate"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(changes).To(Equal([]migrations.Change{
+			{Kind: migrations.Renamed, From: ".properties.routes", To: ".web.routes", FromType: "collection", ToType: "collection"},
+			{Kind: migrations.Retyped, From: ".properties.routes.port", To: ".web.routes.port", FromType: "integer", ToType: "string"},
+			{Kind: migrations.Removed, From: ".properties.routes.weight", FromType: "integer"},
+			{Kind: migrations.Renamed, From: ".properties.tls.enabled.cert", To: ".properties.tls.enabled.certificate", FromType: "rsa_cert_credentials", ToType: "rsa_cert_credentials"},
+		}))
+	})
+
+	It("flags removed property blueprints", func() {
+		changes, err := migrations.Compare(before, payload(`{property_blueprints: [{name: port, type: integer}]}`), nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(changes).To(HaveLen(5))
+		Expect(changes[0].Kind).To(Equal(migrations.Removed))
+		Expect(changes[0].String()).To(Equal(".properties.enabled was removed"))
+		Expect(changes[0].NeedsAttention()).To(BeTrue())
+	})
+
+	It("errors on renames of unknown property blueprints", func() {
+		_, err := migrations.Compare(before, after, map[string]string{".properties.missing": ".properties.port"})
+		Expect(err).To(MatchError("renamed property .properties.missing does not exist in the previous tile"))
+
+		_, err = migrations.Compare(before, after, map[string]string{".properties.port": ".properties.missing"})
+		Expect(err).To(MatchError("renamed property .properties.missing does not exist in the current tile"))
+	})
+
+	It("creates a script moving the renamed values", func() {
+		changes, err := migrations.Compare(before, after, map[string]string{
+			".properties.port": ".properties.listen_port",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(string(migrations.Script(changes))).To(Equal(`exports.migrate = function(input) {
+  var properties = input.properties;
+
+  // TODO: .properties.log_level was removed, its value has to be migrated by hand
+  // TODO: .properties.timeout was retyped from integer to string, its value has to be migrated by hand
+  // .properties.legacy was renamed to .web.legacy
+  if (properties['.properties.legacy'] !== undefined) {
+    properties['.web.legacy'] = properties['.properties.legacy'];
+    delete properties['.properties.legacy'];
+  }
+  // .properties.port was renamed to .properties.listen_port
+  if (properties['.properties.port'] !== undefined) {
+    properties['.properties.listen_port'] = properties['.properties.port'];
+    delete properties['.properties.port'];
+  }
+
+  return input;
+};
+`))
+	})
+
+	It("creates a script noting the changes that need attention", func() {
+		Expect(string(migrations.Script([]migrations.Change{{Kind: migrations.Removed, From: ".properties.port"}}))).To(Equal(`exports.migrate = function(input) {
+  var properties = input.properties;
+
+  // TODO: .properties.port was removed, its value has to be migrated by hand
+
+  return input;
+};
+`))
+	})
+
+	It("creates no script when nothing was renamed or needs attention", func() {
+		Expect(migrations.Script([]migrations.Change{
+			{Kind: migrations.Retyped, From: ".properties.host", To: ".properties.host", FromType: "string", ToType: "text"},
+			{Kind: migrations.Suggested, From: ".properties.port", To: ".properties.listen_port"},
+		})).To(BeNil())
+	})
+})
@@ -2,22 +2,168 @@ package render
 
 import (
 	"fmt"
+	"sort"
+
 	"github.com/jtarchie/tile-builder/manifest"
 	"github.com/jtarchie/tile-builder/metadata"
-	"sort"
+	"gopkg.in/yaml.v2"
 )
 
 func AsBoshManifest(payload metadata.Payload) (*manifest.Payload, error) {
 	deployment := &manifest.Payload{
 		Name: fmt.Sprintf("%s-guid", payload.Name),
+		Update: manifest.Update{
+			Canaries:        1,
+			MaxInFlight:     1,
+			CanaryWatchTime: "30000-300000",
+			UpdateWatchTime: "30000-300000",
+		},
 	}
 
 	addReleases(payload, deployment)
 	addStemcells(payload, deployment)
+	addVariables(payload, deployment)
+
+	err := addInstanceGroups(payload, deployment)
+	if err != nil {
+		return nil, err
+	}
 
 	return deployment, nil
 }
 
+func addInstanceGroups(payload metadata.Payload, deployment *manifest.Payload) error {
+	for _, jobType := range payload.JobTypes {
+		stemcell := jobType.UseStemcell
+		if stemcell == "" {
+			stemcell = payload.StemcellCriteria.OS
+		}
+
+		serial := jobType.Serial
+		instanceGroup := manifest.InstanceGroup{
+			Name:      jobType.Name,
+			AZs:       []string{"((az))"},
+			Instances: jobType.InstanceDefinition.Default,
+			Stemcell:  stemcell,
+			Networks:  []manifest.Network{{Name: "((network))"}},
+			Update: &manifest.Update{
+				MaxInFlight: jobType.MaxInFlight,
+				Serial:      &serial,
+			},
+		}
+
+		addVMResources(jobType, &instanceGroup)
+
+		// the manifest of a job type with several templates is applied once,
+		// to the instance group, instead of to each of its jobs
+		if len(jobType.Templates) > 1 && jobType.Manifest != "" {
+			err := yaml.Unmarshal([]byte(jobType.Manifest), &instanceGroup.Properties)
+			if err != nil {
+				return fmt.Errorf("could not parse manifest for job type %s: %s", jobType.Name, err)
+			}
+		}
+
+		for _, template := range jobType.Templates {
+			job, err := jobFromTemplate(jobType, template)
+			if err != nil {
+				return err
+			}
+
+			instanceGroup.Jobs = append(instanceGroup.Jobs, job)
+		}
+
+		deployment.InstanceGroups = append(deployment.InstanceGroups, instanceGroup)
+	}
+
+	return nil
+}
+
+func jobFromTemplate(jobType metadata.JobType, template metadata.Template) (manifest.Job, error) {
+	job := manifest.Job{
+		Name:    template.Name,
+		Release: template.Release,
+	}
+
+	err := yaml.Unmarshal([]byte(template.Consumes), &job.Consumes)
+	if err != nil {
+		return manifest.Job{}, fmt.Errorf("could not parse consumes for job %s in job type %s: %s", template.Name, jobType.Name, err)
+	}
+
+	err = yaml.Unmarshal([]byte(template.Provides), &job.Provides)
+	if err != nil {
+		return manifest.Job{}, fmt.Errorf("could not parse provides for job %s in job type %s: %s", template.Name, jobType.Name, err)
+	}
+
+	properties := template.Manifest
+	if properties == "" && len(jobType.Templates) == 1 {
+		properties = jobType.Manifest
+	}
+
+	err = yaml.Unmarshal([]byte(properties), &job.Properties)
+	if err != nil {
+		return manifest.Job{}, fmt.Errorf("could not parse manifest for job %s in job type %s: %s", template.Name, jobType.Name, err)
+	}
+
+	return job, nil
+}
+
+func addVMResources(jobType metadata.JobType, instanceGroup *manifest.InstanceGroup) {
+	var (
+		resources manifest.VMResources
+		found     bool
+	)
+
+	for _, definition := range jobType.ResourceDefinitions {
+		value, ok := asInt(definition.Default)
+		if !ok {
+			continue
+		}
+
+		switch definition.Name {
+		case "cpu":
+			resources.CPU = value
+			found = true
+		case "ram":
+			resources.RAM = value
+			found = true
+		case "ephemeral_disk":
+			resources.EphemeralDiskSize = value
+			found = true
+		case "persistent_disk":
+			instanceGroup.PersistentDisk = value
+		}
+	}
+
+	if found {
+		instanceGroup.VMResources = &resources
+	}
+}
+
+func addVariables(payload metadata.Payload, deployment *manifest.Payload) {
+	for _, variable := range payload.Variables {
+		deployment.Variables = append(deployment.Variables, manifest.Variable{
+			Name:    variable.Name,
+			Type:    variable.Type,
+			Options: variable.Options,
+		})
+	}
+}
+
+func asInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+
+	return 0, false
+}
+
 func addStemcells(payload metadata.Payload, deployment *manifest.Payload) {
 	stemcells := []metadata.StemcellCriteria{payload.StemcellCriteria}
 	stemcells = append(stemcells, payload.AdditionalStemcellsCriteria...)
@@ -33,17 +33,67 @@ var _ = Describe("BoshManifest", func() {
 					},
 				},
 				StemcellCriteria: metadata.StemcellCriteria{
-					OS:                         "ubuntu-xenial",
-					Version:                    "319.70",
+					OS:      "ubuntu-xenial",
+					Version: "319.70",
 				},
 				AdditionalStemcellsCriteria: []metadata.StemcellCriteria{
 					{
-						OS:                         "windows-2019",
-						Version:                    "12.3",
+						OS:      "windows-2019",
+						Version: "12.3",
+					},
+				},
+				Variables: []metadata.Variable{
+					{
+						Name: "some-password",
+						Type: "password",
+					},
+				},
+				JobTypes: []metadata.JobType{
+					{
+						Name:        "some-job",
+						MaxInFlight: "20%",
+						InstanceDefinition: metadata.InstanceDefinition{
+							Default: 3,
+						},
+						ResourceDefinitions: []metadata.ResourceDefinition{
+							{Name: "cpu", Default: 2},
+							{Name: "ram", Default: 4096},
+							{Name: "ephemeral_disk", Default: 10240},
+							{Name: "persistent_disk", Default: 20480},
+						},
+						Manifest: "some: {property: ((.properties.some__property.value))}",
+						Templates: []metadata.Template{
+							{
+								Name:     "some",
+								Release:  "some-release",
+								Consumes: "provided: {from: other-provided}",
+								Provides: "provided: {as: some-provided}",
+							},
+							{
+								Name:     "other",
+								Release:  "another-release",
+								Manifest: "other: true",
+							},
+						},
+					},
+					{
+						Name:        "windows-job",
+						MaxInFlight: 1,
+						Serial:      true,
+						UseStemcell: "windows-2019",
+						InstanceDefinition: metadata.InstanceDefinition{
+							Default: 1,
+						},
+						Manifest: "windows: {property: true}",
+						Templates: []metadata.Template{
+							{
+								Name:    "windows",
+								Release: "some-release",
+							},
+						},
 					},
 				},
 			},
-
 			)
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -83,5 +133,72 @@ var _ = Describe("BoshManifest", func() {
 				},
 			}))
 		})
+
+		It("contains the update block", func() {
+			Expect(payload.Update).To(Equal(manifest.Update{
+				Canaries:        1,
+				MaxInFlight:     1,
+				CanaryWatchTime: "30000-300000",
+				UpdateWatchTime: "30000-300000",
+			}))
+		})
+
+		It("contains the variables", func() {
+			Expect(payload.Variables).To(Equal([]manifest.Variable{
+				{
+					Name: "some-password",
+					Type: "password",
+				},
+			}))
+		})
+
+		It("contains an instance group for each job type", func() {
+			instanceGroups := payload.InstanceGroups
+			Expect(instanceGroups).To(HaveLen(2))
+
+			instanceGroup := instanceGroups[0]
+			Expect(instanceGroup.Name).To(Equal("some-job"))
+			Expect(instanceGroup.Instances).To(Equal(3))
+			Expect(instanceGroup.Stemcell).To(Equal("ubuntu-xenial"))
+			Expect(instanceGroup.AZs).To(Equal([]string{"((az))"}))
+			Expect(instanceGroup.Networks).To(Equal([]manifest.Network{{Name: "((network))"}}))
+			Expect(instanceGroup.VMResources).To(Equal(&manifest.VMResources{
+				CPU:               2,
+				RAM:               4096,
+				EphemeralDiskSize: 10240,
+			}))
+			Expect(instanceGroup.PersistentDisk).To(Equal(20480))
+			Expect(instanceGroup.Update.MaxInFlight).To(Equal("20%"))
+			Expect(*instanceGroup.Update.Serial).To(BeFalse())
+
+			Expect(instanceGroup.Jobs).To(HaveLen(2))
+			Expect(instanceGroup.Jobs[0].Name).To(Equal("some"))
+			Expect(instanceGroup.Jobs[0].Release).To(Equal("some-release"))
+			Expect(instanceGroup.Jobs[0].Consumes).To(HaveKeyWithValue("provided", map[interface{}]interface{}{"from": "other-provided"}))
+			Expect(instanceGroup.Jobs[0].Provides).To(HaveKeyWithValue("provided", map[interface{}]interface{}{"as": "some-provided"}))
+			Expect(instanceGroup.Properties).To(Equal(map[string]interface{}{
+				"some": map[interface{}]interface{}{"property": "((.properties.some__property.value))"},
+			}))
+			Expect(instanceGroup.Jobs[0].Properties).To(BeNil())
+			Expect(instanceGroup.Jobs[1].Name).To(Equal("other"))
+			Expect(instanceGroup.Jobs[1].Release).To(Equal("another-release"))
+			Expect(instanceGroup.Jobs[1].Properties).To(Equal(map[string]interface{}{"other": true}))
+
+			instanceGroup = instanceGroups[1]
+			Expect(instanceGroup.Name).To(Equal("windows-job"))
+			Expect(instanceGroup.Stemcell).To(Equal("windows-2019"))
+			Expect(instanceGroup.VMResources).To(BeNil())
+			Expect(*instanceGroup.Update.Serial).To(BeTrue())
+			Expect(instanceGroup.Properties).To(BeNil())
+			Expect(instanceGroup.Jobs[0].Properties).To(Equal(map[string]interface{}{
+				"windows": map[interface{}]interface{}{"property": true},
+			}))
+		})
+
+		It("is a valid manifest", func() {
+			messages, err := payload.Validate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(BeEmpty())
+		})
 	})
 })
@@ -0,0 +1,222 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/jtarchie/tile-builder/configuration"
+	"github.com/jtarchie/tile-builder/manifest"
+	"github.com/jtarchie/tile-builder/metadata"
+)
+
+var accessorPattern = regexp.MustCompile(`\(\(\s*(\.[^()\s]+)\s*\)\)`)
+
+// credentialFields maps an accessor to the keys of a credential value (as
+// written in a product config) that it can be read from.
+var credentialFields = map[string][]string{
+	"certificate": {"cert_pem", "certificate"},
+	"private_key": {"private_key_pem", "private_key"},
+	"public_key":  {"public_key_pem", "public_key"},
+	"identity":    {"identity"},
+	"password":    {"password"},
+	"secret":      {"secret"},
+}
+
+type UnresolvedReferencesError struct {
+	References []string
+}
+
+func (u UnresolvedReferencesError) Error() string {
+	return fmt.Sprintf("could not resolve references: %s", strings.Join(u.References, ", "))
+}
+
+// Resolver substitutes `((.properties.*))` style accessors with the values
+// from a product config, falling back to the defaults of the property blueprints.
+type Resolver struct {
+	payload metadata.Payload
+	product configuration.Product
+}
+
+func NewResolver(payload metadata.Payload, product configuration.Product) *Resolver {
+	return &Resolver{
+		payload: payload,
+		product: product,
+	}
+}
+
+func (r *Resolver) ResolveManifest(deployment *manifest.Payload) error {
+	unresolved := map[string]bool{}
+
+	for i, instanceGroup := range deployment.InstanceGroups {
+		if instanceGroup.Properties != nil {
+			deployment.InstanceGroups[i].Properties = r.resolve(instanceGroup.Properties, unresolved).(map[string]interface{})
+		}
+
+		for j, job := range instanceGroup.Jobs {
+			for _, section := range []*map[string]interface{}{&job.Properties, &job.Consumes, &job.Provides} {
+				if *section == nil {
+					continue
+				}
+
+				resolved := r.resolve(*section, unresolved)
+				*section = resolved.(map[string]interface{})
+			}
+
+			deployment.InstanceGroups[i].Jobs[j] = job
+		}
+	}
+
+	return unresolvedError(unresolved)
+}
+
+func (r *Resolver) Resolve(value interface{}) (interface{}, error) {
+	unresolved := map[string]bool{}
+
+	resolved := r.resolve(value, unresolved)
+
+	err := unresolvedError(unresolved)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolved, nil
+}
+
+func (r *Resolver) resolve(value interface{}, unresolved map[string]bool) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.resolveString(v, unresolved)
+	case map[string]interface{}:
+		resolved := map[string]interface{}{}
+		for key, item := range v {
+			resolved[key] = r.resolve(item, unresolved)
+		}
+		return resolved
+	case map[interface{}]interface{}:
+		resolved := map[interface{}]interface{}{}
+		for key, item := range v {
+			resolved[key] = r.resolve(item, unresolved)
+		}
+		return resolved
+	case []interface{}:
+		resolved := []interface{}{}
+		for _, item := range v {
+			resolved = append(resolved, r.resolve(item, unresolved))
+		}
+		return resolved
+	}
+
+	return value
+}
+
+func (r *Resolver) resolveString(value string, unresolved map[string]bool) interface{} {
+	matches := accessorPattern.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return value
+	}
+
+	// a value that is only an accessor keeps the type of the resolved value
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(value) {
+		accessor := value[matches[0][2]:matches[0][3]]
+
+		resolved, found := r.lookup(accessor)
+		if !found {
+			unresolved[accessor] = true
+			return value
+		}
+
+		return resolved
+	}
+
+	return accessorPattern.ReplaceAllStringFunc(value, func(match string) string {
+		accessor := accessorPattern.FindStringSubmatch(match)[1]
+
+		resolved, found := r.lookup(accessor)
+		if !found {
+			unresolved[accessor] = true
+			return match
+		}
+
+		if resolved == nil {
+			return ""
+		}
+
+		return fmt.Sprintf("%v", resolved)
+	})
+}
+
+func (r *Resolver) lookup(accessor string) (interface{}, bool) {
+	index := strings.LastIndex(accessor, ".")
+	if index <= 0 {
+		return nil, false
+	}
+
+	reference, field := accessor[:index], accessor[index+1:]
+
+	value, found := r.value(reference)
+	if !found {
+		return nil, false
+	}
+
+	if field == "value" {
+		return value, true
+	}
+
+	keys, ok := credentialFields[field]
+	if !ok {
+		return nil, false
+	}
+
+	for _, key := range keys {
+		switch credential := value.(type) {
+		case map[string]interface{}:
+			if v, ok := credential[key]; ok {
+				return v, true
+			}
+		case map[interface{}]interface{}:
+			if v, ok := credential[key]; ok {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+func (r *Resolver) value(reference string) (interface{}, bool) {
+	if strings.HasPrefix(reference, "..") {
+		return nil, false
+	}
+
+	pb, found := r.payload.FindPropertyBlueprintFromPropertyInput(reference)
+	if !found {
+		return nil, false
+	}
+
+	if property, ok := r.product.ProductProperties[reference]; ok {
+		return property.Value, true
+	}
+
+	if pb.Default != nil || pb.Optional {
+		return pb.Default, true
+	}
+
+	return nil, false
+}
+
+func unresolvedError(unresolved map[string]bool) error {
+	if len(unresolved) == 0 {
+		return nil
+	}
+
+	references := []string{}
+	for reference := range unresolved {
+		references = append(references, reference)
+	}
+
+	sort.Strings(references)
+
+	return UnresolvedReferencesError{References: references}
+}
@@ -0,0 +1,133 @@
+package render_test
+
+import (
+	"github.com/jtarchie/tile-builder/configuration"
+	"github.com/jtarchie/tile-builder/manifest"
+	"github.com/jtarchie/tile-builder/metadata"
+	"github.com/jtarchie/tile-builder/render"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Resolver", func() {
+	var resolver *render.Resolver
+
+	BeforeEach(func() {
+		payload := metadata.Payload{
+			PropertyBlueprints: []metadata.PropertyBlueprint{
+				{Name: "string", Type: "string"},
+				{Name: "integer", Type: "integer", Default: 10},
+				{Name: "optional", Type: "string", Optional: true},
+				{Name: "required", Type: "string"},
+				{Name: "certificate", Type: "rsa_cert_credentials"},
+				{Name: "credentials", Type: "simple_credentials"},
+			},
+			JobTypes: []metadata.JobType{
+				{
+					Name: "cloud_controller",
+					PropertyBlueprints: []metadata.PropertyBlueprint{
+						{Name: "system_domain", Type: "domain"},
+					},
+				},
+			},
+		}
+
+		product := configuration.Product{
+			ProductProperties: map[string]configuration.Property{
+				".properties.string": {Value: "some-string"},
+				".properties.certificate": {Value: map[interface{}]interface{}{
+					"cert_pem":        "some-cert",
+					"private_key_pem": "some-key",
+				}},
+				".properties.credentials": {Value: map[interface{}]interface{}{
+					"identity": "some-user",
+					"password": "some-password",
+				}},
+				".cloud_controller.system_domain": {Value: "sys.example.com"},
+			},
+		}
+
+		resolver = render.NewResolver(payload, product)
+	})
+
+	It("substitutes property values", func() {
+		value, err := resolver.Resolve(map[string]interface{}{
+			"string":   "((.properties.string.value))",
+			"integer":  "(( .properties.integer.value ))",
+			"optional": "((.properties.optional.value))",
+			"list":     []interface{}{"((.properties.string.value))"},
+			"embedded": "https://api.((.cloud_controller.system_domain.value)):((.properties.integer.value))",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(map[string]interface{}{
+			"string":   "some-string",
+			"integer":  10,
+			"optional": nil,
+			"list":     []interface{}{"some-string"},
+			"embedded": "https://api.sys.example.com:10",
+		}))
+	})
+
+	It("substitutes credential sub-fields", func() {
+		value, err := resolver.Resolve(map[interface{}]interface{}{
+			"certificate": "((.properties.certificate.certificate))",
+			"private_key": "((.properties.certificate.private_key))",
+			"identity":    "((.properties.credentials.identity))",
+			"password":    "((.properties.credentials.password))",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(map[interface{}]interface{}{
+			"certificate": "some-cert",
+			"private_key": "some-key",
+			"identity":    "some-user",
+			"password":    "some-password",
+		}))
+	})
+
+	It("reports all unresolved references", func() {
+		_, err := resolver.Resolve([]interface{}{
+			"((.properties.required.value))",
+			"((.properties.missing.value))",
+			"((.properties.string.private_key))",
+			"((..cf.cloud_controller.system_domain.value))",
+		})
+		Expect(err).To(Equal(render.UnresolvedReferencesError{
+			References: []string{
+				"..cf.cloud_controller.system_domain.value",
+				".properties.missing.value",
+				".properties.required.value",
+				".properties.string.private_key",
+			},
+		}))
+	})
+
+	It("resolves the properties of a BOSH manifest", func() {
+		deployment := &manifest.Payload{
+			InstanceGroups: []manifest.InstanceGroup{
+				{
+					Properties: map[string]interface{}{
+						"shared": "((.properties.string.value))",
+					},
+					Jobs: []manifest.Job{
+						{
+							Properties: map[string]interface{}{
+								"some": map[interface{}]interface{}{
+									"property": "((.properties.string.value))",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		err := resolver.ResolveManifest(deployment)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deployment.InstanceGroups[0].Properties).To(Equal(map[string]interface{}{"shared": "some-string"}))
+		Expect(deployment.InstanceGroups[0].Jobs[0].Properties).To(Equal(map[string]interface{}{
+			"some": map[interface{}]interface{}{
+				"property": "some-string",
+			},
+		}))
+	})
+})